@@ -0,0 +1,93 @@
+package ejson
+
+import "strings"
+
+// altDelims是"(a|b)"中未加引号的key的结束字符。
+const altDelims = ".[]()|\\\""
+
+// parseAlt解析"(a|b|c)"，每个候选项是一个key，可以用双引号括起来或用'\'转义，p.pos指向'('。
+func (p *keyParser) parseAlt(start int) (segment, error) {
+	p.pos++ // "("
+	var alts []segment
+	for {
+		var seg segment
+		if p.peek() == '"' {
+			key, err := p.parseQuoted(start)
+			if err != nil {
+				return segment{}, err
+			}
+			seg = segment{kind: segKey, key: key}
+		} else {
+			var b strings.Builder
+			for !p.eof() {
+				c := p.peek()
+				if c == '\\' {
+					if p.pos+1 >= len(p.key) {
+						return segment{}, p.errorf(start, "trailing '\\'")
+					}
+					b.WriteByte(p.key[p.pos+1])
+					p.pos += 2
+					continue
+				}
+				if strings.IndexByte(altDelims, c) >= 0 {
+					break
+				}
+				b.WriteByte(c)
+				p.pos++
+			}
+			if b.Len() == 0 && p.peek() != '|' && p.peek() != ')' {
+				if p.eof() {
+					return segment{}, p.errorf(start, "missing ')'")
+				}
+				return segment{}, p.errorf(start, "unexpected '%c' in alternation", p.peek())
+			}
+			seg = keySegment(b.String())
+		}
+		alts = append(alts, seg)
+
+		switch p.peek() {
+		case '|':
+			p.pos++
+		case ')':
+			p.pos++
+			if c := p.peek(); c != 0 && c != '.' && c != '[' {
+				return segment{}, p.errorf(start, "unexpected '%c' after ')'", c)
+			}
+			return segment{kind: segAlt, alts: alts}, nil
+		case 0:
+			return segment{}, p.errorf(start, "missing ')'")
+		default:
+			return segment{}, p.errorf(start, "unexpected '%c' in alternation", p.peek())
+		}
+	}
+}
+
+// getAlt返回第一个存在的候选值，都不存在时返回第一个候选值，写入后将创建它。
+func (s segment) getAlt(j *JSON) *JSON {
+	for _, alt := range s.alts {
+		if v := alt.get(j); v.Exists() {
+			return v
+		}
+	}
+	return s.alts[0].get(j)
+}
+
+// childAlt在已展开的object或array中返回第一个存在的候选值。
+func (s segment) childAlt(j *JSON) *JSON {
+	for _, alt := range s.alts {
+		if v := alt.child(j); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// lookupAlt在原始json中返回第一个存在的候选值。
+func (s segment) lookupAlt(raw []byte) []byte {
+	for _, alt := range s.alts {
+		if v := alt.lookup(raw); len(v) > 0 {
+			return v
+		}
+	}
+	return nil
+}
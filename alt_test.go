@@ -0,0 +1,58 @@
+package ejson
+
+import "testing"
+
+func TestAlt(t *testing.T) {
+	g := FromString(`{"data":{"errcode":2,"code":3,"a.b":4},"result":{"items":[1]},"list":[{"name":"a"},{"title":"b"}],"s":"{\"code\":5}"}`)
+	cases := map[string]string{
+		"data.(err_code|errcode|code)": `2`,
+		"data.(code|errcode)":          `3`,
+		"data.(x|y)":                   ``,
+		`data.(x|"a.b")`:               `4`,
+		`data.(x|a\.b)`:                `4`,
+		"(result|data).items[0]":       `1`,
+		"(data|result).items[0]":       ``,
+		"list[*].(name|title)":         `["a","b"]`,
+		"list.(x|1).title":             `"b"`,
+		"s.(err|code)":                 `5`,
+		"..(errcode|items)":            `[2,[1]]`,
+	}
+	for key, want := range cases {
+		if s := g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get %v: %s, should be %s", key, s, want)
+		}
+		if s := g.Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup %v: %s, should be %s", key, s, want)
+		}
+		if s := g.GetMany(key)[0].UnsafeString(); s != want {
+			t.Fatalf("get many %v: %s, should be %s", key, s, want)
+		}
+	}
+
+	for _, key := range []string{"(a|b", "(a|b)c", "(a.b|c)", "(a|(b))"} {
+		if _, err := g.GetE(key); err == nil {
+			t.Fatalf("%v should be invalid", key)
+		}
+	}
+	if s := QuoteKey("(a|b)"); s != `"(a|b)"` {
+		t.Fatalf("quote key: %s", s)
+	}
+}
+
+func TestAltSet(t *testing.T) {
+	g := FromString(`{"data":{"code":1}}`)
+	g.Get("data.(errcode|code)").Set(2)
+	if s := g.String(); s != `{"data":{"code":2}}` {
+		t.Fatalf("set existing alternative: %s", s)
+	}
+
+	g.Get("data.(msg|message)").Set("ok")
+	if s := g.String(); s != `{"data":{"code":2,"msg":"ok"}}` {
+		t.Fatalf("set first alternative: %s", s)
+	}
+
+	g.Get("(result|data).code").Remove()
+	if s := g.String(); s != `{"data":{"msg":"ok"}}` {
+		t.Fatalf("remove alternative: %s", s)
+	}
+}
@@ -1,6 +1,10 @@
 package ejson
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
 
 type array struct {
 	values []*JSON
@@ -22,18 +26,15 @@ func (g *JSON) asArray() bool {
 		return true
 	}
 
-	var elems []json.RawMessage
-	err := json.Unmarshal(g.getRaw(), &elems)
-	if err != nil {
+	var values []*JSON
+	ok := scanArray(g.getRaw(), func(_ int, val []byte) bool {
+		values = append(values, &JSON{raw: val, parent: g})
+		return true
+	})
+	if !ok {
 		return false
 	}
-	g.array = &array{parent: g}
-	if len(elems) > 0 {
-		g.array.values = make([]*JSON, len(elems))
-		for i := range g.array.values {
-			g.array.values[i] = &JSON{raw: elems[i], parent: g}
-		}
-	}
+	g.array = &array{parent: g, values: values}
 	return true
 }
 
@@ -69,34 +70,35 @@ func (a *array) unmount() {
 }
 
 func (a *array) Index(i int) *JSON {
-	if 0 <= i && i < len(a.values) {
-		return a.values[i]
-	}
-	if i < 0 && len(a.values) > 0 {
-		return a.values[i%len(a.values)+len(a.values)]
+	if idx, ok := normIndex(i, len(a.values)); ok {
+		return a.values[idx]
 	}
 
 	g := &JSON{parent: a.parent}
 
 	parent := a.parent
 	g.update = a.update.append(func() {
-		if a.parent != parent {
+		if a.parent != parent || g.parent != parent || a.contains(g) {
+			// 已经写入的值可能因插入、删除而移动，不能再按i写入
 			return
 		}
 
 		if i < 0 {
-			if len(a.values) > 0 {
-				a.values[i%len(a.values)+len(a.values)] = g
+			if idx, ok := normIndex(i, len(a.values)); ok {
+				a.values[idx] = g
 			} else {
 				a.values = append(a.values, g)
 			}
 		} else {
-			if len(a.values) < i+1 {
-				values := make([]*JSON, i+1)
-				copy(values, a.values)
-				a.values = values
+			// 跳过的位置写入null，array中不留空位
+			for len(a.values) < i {
+				a.values = append(a.values, &JSON{raw: []byte("null"), parent: parent})
+			}
+			if i < len(a.values) {
+				a.values[i] = g
+			} else {
+				a.values = append(a.values, g)
 			}
-			a.values[i] = g
 		}
 	})
 
@@ -111,3 +113,136 @@ func (a *array) Remove(g *JSON) {
 		}
 	}
 }
+
+func (a *array) contains(g *JSON) bool {
+	for _, v := range a.values {
+		if v == g {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending返回一个不存在的值，写入后追加到array末尾，front为true时插入到开头。
+func (a *array) Pending(front bool) *JSON {
+	g := &JSON{parent: a.parent}
+
+	parent := a.parent
+	g.update = a.update.append(func() {
+		if a.parent != parent || g.parent != parent || a.contains(g) {
+			return
+		}
+		if front {
+			a.values = append([]*JSON{g}, a.values...)
+		} else {
+			a.values = append(a.values, g)
+		}
+	})
+
+	return g
+}
+
+// Append将vs追加到array末尾。当前*JSON不存在或为null时将创建array，
+// 是其它非array值时返回错误。对Get返回的多值结果，追加到每个匹配值。
+func (g *JSON) Append(vs ...any) error {
+	_, err := g.splice(math.MaxInt, 0, vs)
+	return err
+}
+
+// Prepend将vs插入到array开头，其它与Append相同。
+func (g *JSON) Prepend(vs ...any) error {
+	_, err := g.splice(0, 0, vs)
+	return err
+}
+
+// Insert将vs插入到array第i个值之前，负数i从尾部计数，如-1表示插入到最后一个值之前。
+// i超出范围时插入到开头或末尾。其它与Append相同。
+func (g *JSON) Insert(i int, vs ...any) error {
+	_, err := g.splice(i, 0, vs)
+	return err
+}
+
+// Splice从array第start个值开始删除deleteCount个值，并在该位置插入vs，返回被删除的值。
+// start为负数时从尾部计数，超出范围时截断到数组两端；deleteCount超出剩余长度时删除到末尾。
+// 其它与Append相同。
+func (g *JSON) Splice(start, deleteCount int, vs ...any) ([]*JSON, error) {
+	return g.splice(start, deleteCount, vs)
+}
+
+func (g *JSON) splice(start, deleteCount int, vs []any) ([]*JSON, error) {
+	raws := make([]json.RawMessage, len(vs))
+	for i, v := range vs {
+		raw, err := marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		raws[i] = raw
+	}
+
+	if g.results != nil {
+		for _, js := range g.results.nodes {
+			if !js.canSplice() {
+				return nil, fmt.Errorf("ejson: cannot splice %v", js.Kind())
+			}
+		}
+		var removed []*JSON
+		for _, js := range g.results.nodes {
+			removed = append(removed, js.spliceRaw(start, deleteCount, raws)...)
+		}
+		return removed, nil
+	}
+
+	if !g.canSplice() {
+		return nil, fmt.Errorf("ejson: cannot splice %v", g.Kind())
+	}
+	return g.spliceRaw(start, deleteCount, raws), nil
+}
+
+// canSplice判断能否对g执行splice：g是array，或不存在、为null。
+func (g *JSON) canSplice() bool {
+	switch g.Kind() {
+	case Array:
+		return g.asArray()
+	case Missing, Null:
+		return true
+	}
+	return false
+}
+
+func (g *JSON) spliceRaw(start, deleteCount int, raws []json.RawMessage) []*JSON {
+	if !g.asArray() {
+		// 不存在或为null，替换为空array
+		g.raw = nil
+		g.object.unmount()
+		g.object = nil
+		g.str.unmount()
+		g.str = nil
+		g.array = &array{parent: g}
+	}
+	a := g.array
+
+	n := len(a.values)
+	if start < 0 {
+		start = max(start+n, 0)
+	}
+	start = min(start, n)
+	deleteCount = min(max(deleteCount, 0), n-start)
+
+	values := make([]*JSON, 0, n-deleteCount+len(raws))
+	values = append(values, a.values[:start]...)
+	for _, raw := range raws {
+		values = append(values, &JSON{raw: raw, parent: g})
+	}
+	values = append(values, a.values[start+deleteCount:]...)
+
+	removed := make([]*JSON, deleteCount)
+	copy(removed, a.values[start:start+deleteCount])
+	for _, v := range removed {
+		if v != nil {
+			v.parent = nil
+		}
+	}
+	a.values = values
+	g.modified()
+	return removed
+}
@@ -11,3 +11,108 @@ func TestIsArray(t *testing.T) {
 		t.Fatalf("[ should be an empty array")
 	}
 }
+
+func TestArrayMutation(t *testing.T) {
+	g := FromString(`{"a":{"items":[1,2,3]}}`)
+	items := g.Get("a.items")
+	_ = g.String() // 缓存raw
+
+	steps := []struct {
+		op   func() error
+		want string
+	}{
+		{func() error { return items.Append(4, 5) }, `{"a":{"items":[1,2,3,4,5]}}`},
+		{func() error { return items.Prepend(0) }, `{"a":{"items":[0,1,2,3,4,5]}}`},
+		{func() error { return items.Insert(2, "x") }, `{"a":{"items":[0,1,"x",2,3,4,5]}}`},
+		{func() error { return items.Insert(-1, "y") }, `{"a":{"items":[0,1,"x",2,3,4,"y",5]}}`},
+		{func() error { return items.Insert(100, "z") }, `{"a":{"items":[0,1,"x",2,3,4,"y",5,"z"]}}`},
+		{func() error { return items.Insert(-100) }, `{"a":{"items":[0,1,"x",2,3,4,"y",5,"z"]}}`},
+		{func() error {
+			removed, err := items.Splice(2, 5, "s")
+			if len(removed) != 5 || removed[0].String() != "x" || removed[0].Parent() != nil {
+				t.Fatalf("removed: %v", removed)
+			}
+			return err
+		}, `{"a":{"items":[0,1,"s",5,"z"]}}`},
+		{func() error {
+			removed, err := items.Splice(-2, 100)
+			if len(removed) != 2 {
+				t.Fatalf("removed: %v", removed)
+			}
+			return err
+		}, `{"a":{"items":[0,1,"s"]}}`},
+	}
+	for i, s := range steps {
+		if err := s.op(); err != nil {
+			t.Fatalf("step %v: %v", i, err)
+		}
+		if str := g.String(); str != s.want {
+			t.Fatalf("step %v: %s, should be %s", i, str, s.want)
+		}
+	}
+
+	// 插入后原有节点仍然有效
+	one := items.ArrayIndex(1)
+	items.Prepend(-1)
+	one.Set(10)
+	if s := g.String(); s != `{"a":{"items":[-1,0,10,"s"]}}` {
+		t.Fatalf("set after insert: %s", s)
+	}
+}
+
+func TestArrayMutationCreate(t *testing.T) {
+	g := FromString(`{"n":null,"o":{},"s":"x"}`)
+	if err := g.Get("n").Append(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Get("a.b").Append(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Get("o").Append(1); err == nil {
+		t.Fatal("append to object should fail")
+	}
+	if err := g.Get("s").Prepend(1); err == nil {
+		t.Fatal("prepend to string should fail")
+	}
+	if err := g.Get("n").Append(func() {}); err == nil {
+		t.Fatal("append unmarshalable value should fail")
+	}
+	if s := g.String(); s != `{"n":[1],"o":{},"s":"x","a":{"b":[1,2]}}` {
+		t.Fatalf("create: %s", s)
+	}
+
+	g = FromString(`{"users":[{"tags":["a"]},{}]}`)
+	if err := g.Get("users[*].tags").Append("b"); err != nil {
+		t.Fatal(err)
+	}
+	if s := g.String(); s != `{"users":[{"tags":["a","b"]},{"tags":["b"]}]}` {
+		t.Fatalf("append results: %s", s)
+	}
+}
+
+func TestArrayPending(t *testing.T) {
+	g := FromString(`{"items":[1,2]}`)
+	g.Get("items[+]").Set(3)
+	g.Get("items[-]").Set(0)
+	g.Get("list[+].id").Set(1)
+	g.Get("list[+].id").Set(2)
+	if s := g.String(); s != `{"items":[0,1,2,3],"list":[{"id":1},{"id":2}]}` {
+		t.Fatalf("pending: %s", s)
+	}
+	if g.Get("items[+]").Exists() || g.Lookup("items[-]").Exists() {
+		t.Fatal("pending position should not exists")
+	}
+	if ptr, err := SmartKeyToPointer("items[+]"); err != nil || ptr != "/items/-" {
+		t.Fatalf("pointer: %q, %v", ptr, err)
+	}
+
+	// 负数下标在空数组上追加，之后的写入不会覆盖其它值
+	a := FromString(`[]`)
+	last := a.ArrayIndex(-1)
+	last.Set(1)
+	a.Append(2)
+	last.Set(3)
+	if s := a.String(); s != `[3,2]` {
+		t.Fatalf("negative index on empty array: %s", s)
+	}
+}
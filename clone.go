@@ -0,0 +1,62 @@
+package ejson
+
+// Clone返回当前*JSON的独立副本，副本是新的根节点，修改副本和原json互不影响。
+// 未修改的原始json在副本和原json间共享，不会复制，只有已经展开的object、array需要复制。
+// 副本保留当前*JSON的key匹配方式，见SetKeyMatch。对Get返回的多值结果，副本是由所有匹配值组成的array。
+func (g *JSON) Clone() *JSON {
+	if g.results != nil {
+		return &JSON{raw: g.getRaw(), match: g.keyMatch()}
+	}
+	c := g.clone(nil)
+	c.match = g.keyMatch()
+	return c
+}
+
+// clone复制g及其已展开的后代，raw按写时复制共享：所有写入都替换raw，而不会修改raw的内容。
+func (g *JSON) clone(parent *JSON) *JSON {
+	c := &JSON{raw: g.raw, parent: parent, match: g.match}
+	if g.object != nil {
+		obj := &object{
+			keys:   make([]string, len(g.object.keys)),
+			entry:  make(map[string]*JSON, len(g.object.entry)),
+			parent: c,
+		}
+		copy(obj.keys, g.object.keys)
+		for key, v := range g.object.entry {
+			obj.entry[key] = v.clone(c)
+		}
+		c.object = obj
+	}
+	if g.array != nil {
+		values := make([]*JSON, len(g.array.values))
+		for i, v := range g.array.values {
+			if v != nil {
+				values[i] = v.clone(c)
+			}
+		}
+		c.array = &array{parent: c, values: values}
+	}
+	if g.str != nil {
+		c.str = &strjson{parent: c}
+		if g.str.value != nil {
+			c.str.value = g.str.value.clone(c)
+		}
+	}
+	return c
+}
+
+// Detach将当前*JSON从json树中移除，并作为新的根节点返回，之前取得的子节点仍属于返回的json。
+// 与Remove不同，Detach之后写入当前*JSON及其子节点不会再写回原json树，尚未写入的值也不会再写入原json树。
+// 返回的json保留原来的key匹配方式，见SetKeyMatch。对Get返回的多值结果，移除所有匹配值，返回由它们组成的array。
+func (g *JSON) Detach() *JSON {
+	if g.results != nil {
+		js := g.Clone()
+		g.results.Remove()
+		return js
+	}
+	g.match = g.keyMatch()
+	g.Remove()
+	g.parent = nil
+	g.update = nil
+	return g
+}
@@ -0,0 +1,100 @@
+package ejson
+
+import "testing"
+
+func TestClone(t *testing.T) {
+	g := FromString(`{"a":{"b":[1,2]},"s":"{\"x\":1}","c":3}`)
+	g.Get("a.b[0]").Set(0)
+	g.Get("s.x").Int()
+
+	c := g.Clone()
+	if s := c.String(); s != g.String() {
+		t.Fatalf("clone: %s", s)
+	}
+	c.Get("a.b[+]").Set(3)
+	c.Get("s.x").Set(2)
+	c.Get("c").Remove()
+	c.Get("d").Set(4)
+	if s := g.String(); s != `{"a":{"b":[0,2]},"s":"{\"x\":1}","c":3}` {
+		t.Fatalf("original modified: %s", s)
+	}
+	if s := c.String(); s != `{"a":{"b":[0,2,3]},"s":"{\"x\":2}","d":4}` {
+		t.Fatalf("clone modified: %s", s)
+	}
+
+	// 修改原json不影响副本
+	c = g.Clone()
+	g.Get("a.b").Append(9)
+	if s := c.Get("a.b").String(); s != `[0,2]` {
+		t.Fatalf("clone after modify original: %s", s)
+	}
+
+	// 子树的副本是新的根节点
+	b := g.Get("a").Clone()
+	if b.Parent() != nil || b.Path() != "" {
+		t.Fatal("clone should be a root")
+	}
+	b.Get("b").Set(nil)
+	if s := g.Get("a").String(); s != `{"b":[0,2,9]}` {
+		t.Fatalf("clone subtree: %s", s)
+	}
+
+	g = FromString(`{"UserID":1}`)
+	g.SetKeyMatch(MatchFold)
+	if c := g.Clone(); c.Get("userid").Int() != 1 {
+		t.Fatal("clone should keep key match")
+	}
+
+	g = FromString(`[{"a":1},{"a":2}]`)
+	if c := g.Get("[*].a").Clone(); c.String() != `[1,2]` {
+		t.Fatalf("clone results: %s", c)
+	}
+	if c := new(JSON).Clone(); c.Exists() {
+		t.Fatal("clone of missing value should not exist")
+	}
+}
+
+func TestDetach(t *testing.T) {
+	g := FromString(`{"a":{"b":{"c":1}},"d":[1,2,3]}`)
+	a := g.Get("a")
+	c := a.Get("b.c")
+	d := a.Detach()
+	if d != a || d.Parent() != nil {
+		t.Fatal("detach should return the node as root")
+	}
+	if s := g.String(); s != `{"d":[1,2,3]}` {
+		t.Fatalf("detach: %s", s)
+	}
+	c.Set(2)
+	d.Get("x").Set(true)
+	if s := d.String(); s != `{"b":{"c":2},"x":true}` {
+		t.Fatalf("detached modified: %s", s)
+	}
+	if s := g.String(); s != `{"d":[1,2,3]}` {
+		t.Fatalf("original after detached modified: %s", s)
+	}
+	if p := c.Path(); p != "b.c" {
+		t.Fatalf("path in detached: %v", p)
+	}
+
+	// 尚未写入的值Detach后不会再写入原json
+	p := g.Get("x.y")
+	p.Detach()
+	p.Set(1)
+	if s := g.String(); s != `{"d":[1,2,3]}` {
+		t.Fatalf("detach pending: %s", s)
+	}
+	q := g.Get("d[5]")
+	q.Detach().Set(1)
+	if s := g.String(); s != `{"d":[1,2,3]}` {
+		t.Fatalf("detach pending index: %s", s)
+	}
+
+	e := g.Get("d[1:]").Detach()
+	if s := e.String(); s != `[2,3]` {
+		t.Fatalf("detach results: %s", s)
+	}
+	if s := g.String(); s != `{"d":[1]}` {
+		t.Fatalf("detach results: %s", s)
+	}
+}
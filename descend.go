@@ -0,0 +1,148 @@
+package ejson
+
+// appendDescendants按先序遍历j及其所有后代，将s在每个值上匹配到的值追加到dst。
+// 内容为object或array的字符串json会自动展开。
+func (s segment) appendDescendants(dst []*JSON, j *JSON) []*JSON {
+	inner := s
+	inner.descend = false
+
+	var walk func(j *JSON)
+	walk = func(j *JSON) {
+		if isStrComposite(j.getRaw()) {
+			j = j.StrJSON()
+		}
+
+		var children []*JSON
+		isArray := false
+		if j.IsArray() && j.asArray() {
+			children = j.array.values
+			isArray = true
+		} else if j.IsObject() && j.asObject() {
+			children = make([]*JSON, len(j.object.keys))
+			for i, key := range j.object.keys {
+				children[i] = j.object.entry[key]
+			}
+		} else {
+			return
+		}
+
+		if inner.kind == segSlice {
+			if isArray {
+				dst = appendSliced(dst, inner, children)
+			}
+		} else if inner.fanout() {
+			for _, c := range children {
+				if inner.accept(cursor{node: c}) {
+					dst = append(dst, c)
+				}
+			}
+		} else if v := inner.child(j); v != nil {
+			dst = append(dst, v)
+		}
+		for _, c := range children {
+			walk(c)
+		}
+	}
+	walk(j)
+	return dst
+}
+
+// lookupDescendants与appendDescendants相同，但不会在json树上创建节点。
+func (s segment) lookupDescendants(dst []cursor, c cursor) []cursor {
+	inner := s
+	inner.descend = false
+
+	var walk func(c cursor)
+	walk = func(c cursor) {
+		node := c.node
+		if node != nil && node.str != nil {
+			node = node.str.value
+			if node == nil {
+				return
+			}
+		}
+		if node != nil && (node.object != nil || node.array != nil) {
+			var children []cursor
+			if node.array != nil {
+				for _, v := range node.array.values {
+					children = append(children, cursor{node: v})
+				}
+			} else {
+				for _, key := range node.object.keys {
+					children = append(children, cursor{node: node.object.entry[key]})
+				}
+			}
+			if inner.fanout() {
+				dst = appendAccepted(dst, inner, children, node.array != nil)
+			} else if v := inner.child(node); v != nil {
+				dst = append(dst, cursor{node: v})
+			}
+			for _, c := range children {
+				walk(c)
+			}
+			return
+		}
+
+		raw := c.raw
+		if node != nil {
+			raw = node.getRaw()
+		}
+		if isStrComposite(raw) {
+			raw = strJSON(raw)
+		}
+		if len(raw) == 0 || (raw[0] != '{' && raw[0] != '[') {
+			return
+		}
+
+		var children []cursor
+		if raw[0] == '[' {
+			if !scanArray(raw, func(_ int, val []byte) bool {
+				children = append(children, cursor{raw: val})
+				return true
+			}) {
+				return
+			}
+		} else {
+			scanObjectDedup(raw, func(_ string, val []byte) {
+				children = append(children, cursor{raw: val})
+			})
+		}
+		if inner.fanout() {
+			dst = appendAccepted(dst, inner, children, raw[0] == '[')
+		} else if v := inner.lookup(raw); len(v) > 0 {
+			dst = append(dst, cursor{raw: v})
+		}
+		for _, c := range children {
+			walk(c)
+		}
+	}
+	walk(c)
+	return dst
+}
+
+// isStrComposite判断raw是否是内容为object或array的字符串json。
+// 内容为数字等简单值的字符串json没有后代，递归查找时无需展开。
+func isStrComposite(raw []byte) bool {
+	if len(raw) == 0 || raw[0] != '"' {
+		return false
+	}
+	s := strJSON(raw)
+	return len(s) > 0 && (s[0] == '{' || s[0] == '[')
+}
+
+// appendAccepted将children中被通配符、过滤或切片片段s匹配到的值追加到dst。
+// isArray表示children是否是数组的所有元素，切片只匹配数组元素。
+func appendAccepted(dst []cursor, s segment, children []cursor, isArray bool) []cursor {
+	if s.kind == segSlice {
+		if isArray {
+			dst = appendSliced(dst, s, children)
+		}
+		return dst
+	}
+	for _, c := range children {
+		if s.accept(c) {
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,62 @@
+package ejson
+
+import "testing"
+
+func TestDescend(t *testing.T) {
+	g := FromString(`{"id":1,"a":{"id":2,"b":[{"id":3},{"x":{"id":4}}]},"c":"{\"id\":5,\"d\":\"[{\\\"id\\\":6}]\"}","e":"7"}`)
+	cases := map[string]string{
+		"..id":     `[1,2,3,4,5,6]`,
+		"a..id":    `[2,3,4]`,
+		"a.b..id":  `[3,4]`,
+		"..x.id":   `[4]`,
+		"..b[0]":   `[{"id":3}]`,
+		"..b..id":  `[3,4]`,
+		"..nope":   `[]`,
+		"e..id":    `[]`,
+		`.."id"`:   `[1,2,3,4,5,6]`,
+		"a.b..[1]": `[{"x":{"id":4}}]`,
+	}
+	for key, want := range cases {
+		if s := g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get %v: %s, should be %s", key, s, want)
+		}
+		if s := FromString(g.UnsafeString()).Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup %v: %s, should be %s", key, s, want)
+		}
+		if s := g.Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup expanded %v: %s, should be %s", key, s, want)
+		}
+	}
+
+	if n := FromString(`{"a":{"b":1},"c":[2,3]}`).Get("..*").Len(); n != 5 {
+		t.Fatalf("..* len: %v", n)
+	}
+
+	// 写入越界下标时跳过的位置是null
+	h := FromString(`{"a":[]}`)
+	h.Get("a[2].x").Set(1)
+	if s := h.Get("..x").UnsafeString(); s != `[1]` {
+		t.Fatalf("get ..x on holes: %s", s)
+	}
+	if s := h.Lookup("..x").UnsafeString(); s != `[1]` {
+		t.Fatalf("lookup ..x on holes: %s", s)
+	}
+
+	for _, key := range []string{"a..", "..", "a...b"} {
+		if _, err := parseSmartKey(key); err == nil {
+			t.Fatalf("%q should be invalid", key)
+		}
+	}
+}
+
+func TestDescendSet(t *testing.T) {
+	g := FromString(`{"price":1,"a":{"price":2,"b":"{\"price\":3}"}}`)
+	g.Get("..price").Set(0)
+	if s := g.UnsafeString(); s != `{"price":0,"a":{"price":0,"b":"{\"price\":0}"}}` {
+		t.Fatalf("set: %s", s)
+	}
+	g.Get("a..price").Remove()
+	if s := g.UnsafeString(); s != `{"price":0,"a":{"b":"{}"}}` {
+		t.Fatalf("remove: %s", s)
+	}
+}
@@ -0,0 +1,359 @@
+package ejson
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+)
+
+// Change是Diff找到的一处变化，与RFC 6902 patch中的操作一一对应。
+type Change struct {
+	Op   string // "add"、"remove"、"replace"或"move"
+	Path string // 变化位置的JSON Pointer，是依次执行之前所有变化后的位置
+	From string // move的源位置，其它操作为空
+	Old  *JSON  // 原值，add时不存在
+	New  *JSON  // 新值，remove时不存在；move时与Old相同
+}
+
+// ArrayDiff是Diff比较array的方式，默认为ArrayByIndex。
+type ArrayDiff struct {
+	lcs bool
+	key string
+}
+
+var (
+	// ArrayByIndex按下标逐个比较，多出的元素在末尾添加或删除。
+	ArrayByIndex = ArrayDiff{}
+	// ArrayByLCS按最长公共子序列比较，插入、删除元素不会使后面的元素都被替换。
+	ArrayByLCS = ArrayDiff{lcs: true}
+)
+
+// ArrayByKey按object元素中key的值识别同一个元素，如ArrayByKey("id")，
+// 位置变化的元素生成move操作，同一元素的修改在移动后比较。
+// array中有元素没有key、key的值重复时，该array改为按ArrayByLCS比较。
+func ArrayByKey(key string) ArrayDiff {
+	return ArrayDiff{key: key}
+}
+
+// Diff比较a、b，返回将a变为b的RFC 6902 patch，以及与patch中操作一一对应的变化列表。
+// a.ApplyPatch(patch)之后a与b相同，a、b本身不会被修改。arrays指定array的比较方式，默认ArrayByIndex。
+// 不存在的a、b视为null。object的key顺序不同不视为变化。
+func Diff(a, b *JSON, arrays ...ArrayDiff) (patch *JSON, changes []Change) {
+	d := &differ{}
+	if len(arrays) > 0 {
+		d.arrays = arrays[0]
+	}
+	if !a.Exists() {
+		a = &JSON{raw: []byte("null")}
+	}
+	if !b.Exists() {
+		b = &JSON{raw: []byte("null")}
+	}
+	d.diff("", view(a), view(b))
+	return changesPatch(d.changes), d.changes
+}
+
+// view返回与g内容相同的独立*JSON，比较时展开节点不会影响g。
+func view(g *JSON) *JSON {
+	return &JSON{raw: g.getRaw()}
+}
+
+type differ struct {
+	arrays  ArrayDiff
+	changes []Change
+}
+
+func (d *differ) add(path string, v *JSON) {
+	d.changes = append(d.changes, Change{Op: "add", Path: path, Old: new(JSON), New: view(v)})
+}
+
+func (d *differ) remove(path string, v *JSON) {
+	d.changes = append(d.changes, Change{Op: "remove", Path: path, Old: view(v), New: new(JSON)})
+}
+
+func (d *differ) move(from, path string, v *JSON) {
+	d.changes = append(d.changes, Change{Op: "move", Path: path, From: from, Old: view(v), New: view(v)})
+}
+
+func (d *differ) diff(path string, x, y *JSON) {
+	if jsonEqual(x, y) {
+		return
+	}
+	switch {
+	case x.IsObject() && y.IsObject() && x.asObject() && y.asObject():
+		d.diffObject(path, x.object, y.object)
+	case x.IsArray() && y.IsArray() && x.asArray() && y.asArray():
+		xs, ys := x.array.values, y.array.values
+		if d.arrays.key != "" && d.diffArrayByKey(path, xs, ys) {
+			return
+		}
+		if d.arrays.lcs || d.arrays.key != "" {
+			d.diffArrayLCS(path, xs, ys)
+		} else {
+			d.diffArrayIndex(path, xs, ys)
+		}
+	default:
+		d.changes = append(d.changes, Change{Op: "replace", Path: path, Old: view(x), New: view(y)})
+	}
+}
+
+func (d *differ) diffObject(path string, x, y *object) {
+	for _, key := range x.keys {
+		if y.entry[key] == nil {
+			d.remove(path+"/"+escapePointerToken(key), x.entry[key])
+		}
+	}
+	for _, key := range y.keys {
+		p := path + "/" + escapePointerToken(key)
+		if old := x.entry[key]; old != nil {
+			d.diff(p, old, y.entry[key])
+		} else {
+			d.add(p, y.entry[key])
+		}
+	}
+}
+
+func elemPath(path string, i int) string {
+	return path + "/" + strconv.Itoa(i)
+}
+
+func (d *differ) diffArrayIndex(path string, xs, ys []*JSON) {
+	n := min(len(xs), len(ys))
+	for i := 0; i < n; i++ {
+		d.diff(elemPath(path, i), xs[i], ys[i])
+	}
+	for i := len(xs) - 1; i >= n; i-- {
+		d.remove(elemPath(path, i), xs[i])
+	}
+	for i := n; i < len(ys); i++ {
+		d.add(elemPath(path, i), ys[i])
+	}
+}
+
+func (d *differ) diffArrayLCS(path string, xs, ys []*JSON) {
+	// lcs[i][j]是xs[i:]、ys[j:]的最长公共子序列长度
+	lcs := make([][]int, len(xs)+1)
+	eq := make([][]bool, len(xs))
+	for i := range lcs {
+		lcs[i] = make([]int, len(ys)+1)
+		if i < len(xs) {
+			eq[i] = make([]bool, len(ys))
+		}
+	}
+	equal := func(i, j int) bool {
+		return i < len(xs) && j < len(ys) && eq[i][j]
+	}
+	for i := len(xs) - 1; i >= 0; i-- {
+		for j := len(ys) - 1; j >= 0; j-- {
+			if eq[i][j] = jsonEqual(xs[i], ys[j]); eq[i][j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// pos是当前元素在执行之前变化后的下标。两个公共元素之间被删除和插入的元素逐个配对比较，
+	// 多出的元素删除或插入。
+	i, j, pos := 0, 0, 0
+	for i < len(xs) || j < len(ys) {
+		if equal(i, j) {
+			i, j, pos = i+1, j+1, pos+1
+			continue
+		}
+		di, dj := i, j
+		for i < len(xs) || j < len(ys) {
+			if equal(i, j) {
+				break
+			}
+			if j == len(ys) || (i < len(xs) && lcs[i+1][j] >= lcs[i][j+1]) {
+				i++
+			} else {
+				j++
+			}
+		}
+		for ; di < i && dj < j; di, dj, pos = di+1, dj+1, pos+1 {
+			d.diff(elemPath(path, pos), xs[di], ys[dj])
+		}
+		for ; di < i; di++ {
+			d.remove(elemPath(path, pos), xs[di])
+		}
+		for ; dj < j; dj, pos = dj+1, pos+1 {
+			d.add(elemPath(path, pos), ys[dj])
+		}
+	}
+}
+
+// elemID返回array元素v的标识，v不是object或没有key时ok为false。
+func elemID(v *JSON, key string) (id string, ok bool) {
+	if !v.IsObject() || !v.asObject() {
+		return "", false
+	}
+	k := v.object.entry[key]
+	if k == nil {
+		return "", false
+	}
+	if k.IsStr() {
+		// 其它值的原始json不以'"'开头，字符串加上'"'前缀后不会与其它值的标识冲突
+		return `"` + k.Str(), true
+	}
+	return string(compactRaw(k.getRaw())), true
+}
+
+// elemIDs返回values中所有元素的标识，有元素没有标识或标识重复时ok为false。
+func elemIDs(values []*JSON, key string) (ids []string, index map[string]int, ok bool) {
+	ids = make([]string, len(values))
+	index = make(map[string]int, len(values))
+	for i, v := range values {
+		id, ok := elemID(v, key)
+		if !ok {
+			return nil, nil, false
+		}
+		if _, dup := index[id]; dup {
+			return nil, nil, false
+		}
+		ids[i] = id
+		index[id] = i
+	}
+	return ids, index, true
+}
+
+// diffArrayByKey按元素标识比较，依次删除、移动、添加元素，最后比较同一元素的内容。
+// 有元素没有标识或标识重复时返回false，不生成任何变化。
+func (d *differ) diffArrayByKey(path string, xs, ys []*JSON) bool {
+	xids, xindex, ok := elemIDs(xs, d.arrays.key)
+	if !ok {
+		return false
+	}
+	yids, yindex, ok := elemIDs(ys, d.arrays.key)
+	if !ok {
+		return false
+	}
+
+	// 删除b中没有的元素
+	var cur []string
+	for i := len(xs) - 1; i >= 0; i-- {
+		if _, ok := yindex[xids[i]]; !ok {
+			d.remove(elemPath(path, i), xs[i])
+		}
+	}
+	for _, id := range xids {
+		if _, ok := yindex[id]; ok {
+			cur = append(cur, id)
+		}
+	}
+
+	// 保留的元素中，按b中顺序最长递增的部分不需要移动，其余元素依次移动到b中前一个元素之后
+	var kept []string
+	for _, id := range yids {
+		if _, ok := xindex[id]; ok {
+			kept = append(kept, id)
+		}
+	}
+	keptIndex := make(map[string]int, len(kept))
+	for k, id := range kept {
+		keptIndex[id] = k
+	}
+	order := make([]int, len(cur))
+	for i, id := range cur {
+		order[i] = keptIndex[id]
+	}
+	stable := make(map[string]bool)
+	for _, i := range longestIncreasing(order) {
+		stable[cur[i]] = true
+	}
+	for k, id := range kept {
+		if stable[id] {
+			continue
+		}
+		from := indexOf(cur, id)
+		cur = append(cur[:from], cur[from+1:]...)
+		to := 0
+		if k > 0 {
+			to = indexOf(cur, kept[k-1]) + 1
+		}
+		cur = append(cur[:to], append([]string{id}, cur[to:]...)...)
+		if from != to {
+			d.move(elemPath(path, from), elemPath(path, to), xs[xindex[id]])
+		}
+	}
+
+	// 添加a中没有的元素，此时已有元素的顺序与b相同
+	for j, id := range yids {
+		if _, ok := xindex[id]; !ok {
+			d.add(elemPath(path, j), ys[j])
+		}
+	}
+	for j, id := range yids {
+		if i, ok := xindex[id]; ok {
+			d.diff(elemPath(path, j), xs[i], ys[j])
+		}
+	}
+	return true
+}
+
+func indexOf(ids []string, id string) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// longestIncreasing返回a中一个最长严格递增子序列的下标。
+func longestIncreasing(a []int) []int {
+	// tails[k]是长度为k+1的递增子序列中，末尾值最小的一个的末尾下标
+	var tails []int
+	prev := make([]int, len(a))
+	for i, v := range a {
+		k := sort.Search(len(tails), func(k int) bool { return a[tails[k]] >= v })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	seq := make([]int, len(tails))
+	if len(tails) > 0 {
+		for k, i := len(seq)-1, tails[len(tails)-1]; k >= 0; k-- {
+			seq[k] = i
+			i = prev[i]
+		}
+	}
+	return seq
+}
+
+// changesPatch将changes转为RFC 6902 patch。
+func changesPatch(changes []Change) *JSON {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, c := range changes {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		op, _ := marshal(c.Op)
+		path, _ := marshal(c.Path)
+		buf.WriteString(`{"op":`)
+		buf.Write(op)
+		if c.Op == "move" {
+			from, _ := marshal(c.From)
+			buf.WriteString(`,"from":`)
+			buf.Write(from)
+		}
+		buf.WriteString(`,"path":`)
+		buf.Write(path)
+		if c.Op == "add" || c.Op == "replace" {
+			buf.WriteString(`,"value":`)
+			buf.Write(c.New.getRaw())
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+	return &JSON{raw: buf.Bytes()}
+}
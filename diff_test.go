@@ -0,0 +1,100 @@
+package ejson
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		arrays ArrayDiff
+		patch  string
+	}{
+		{`{"a":1,"b":2}`, `{"b":2,"a":1}`, ArrayByIndex, `[]`},
+		{`{"a":1,"b":{"c":1}}`, `{"b":{"c":2},"d":[1]}`, ArrayByIndex,
+			`[{"op":"remove","path":"/a"},{"op":"replace","path":"/b/c","value":2},{"op":"add","path":"/d","value":[1]}]`},
+		{`{"a/b":1}`, `{"a/b":2}`, ArrayByIndex, `[{"op":"replace","path":"/a~1b","value":2}]`},
+		{`1`, `"1"`, ArrayByIndex, `[{"op":"replace","path":"","value":"1"}]`},
+		{``, `{"a":1}`, ArrayByIndex, `[{"op":"replace","path":"","value":{"a":1}}]`},
+		{`[1,2,3]`, `[1,3]`, ArrayByIndex,
+			`[{"op":"replace","path":"/1","value":3},{"op":"remove","path":"/2"}]`},
+		{`[1,2,3]`, `[1,3]`, ArrayByLCS, `[{"op":"remove","path":"/1"}]`},
+		{`[1,2,3]`, `[0,1,2,3,4]`, ArrayByLCS,
+			`[{"op":"add","path":"/0","value":0},{"op":"add","path":"/4","value":4}]`},
+		{`[1,{"a":1},3]`, `[1,{"a":2},3]`, ArrayByLCS, `[{"op":"replace","path":"/1/a","value":2}]`},
+		{`[{"id":1},{"id":2},{"id":3}]`, `[{"id":2},{"id":3},{"id":1}]`, ArrayByKey("id"),
+			`[{"op":"move","from":"/0","path":"/2"}]`},
+		{`[{"id":1,"v":0},{"id":2},{"id":3}]`, `[{"id":3},{"id":1,"v":1},{"id":4}]`, ArrayByKey("id"),
+			`[{"op":"remove","path":"/1"},{"op":"move","from":"/0","path":"/1"},{"op":"add","path":"/2","value":{"id":4}}` +
+				`,{"op":"replace","path":"/1/v","value":1}]`},
+		{`[{"id":"1"},{"id":1}]`, `[{"id":1},{"id":"1"}]`, ArrayByKey("id"), `[{"op":"move","from":"/0","path":"/1"}]`},
+		// 没有id时按LCS比较
+		{`[1,2,3]`, `[1,3]`, ArrayByKey("id"), `[{"op":"remove","path":"/1"}]`},
+	}
+	for _, c := range cases {
+		a, b := FromString(c.a), FromString(c.b)
+		patch, changes := Diff(a, b, c.arrays)
+		if s := patch.String(); s != c.patch {
+			t.Fatalf("diff %s %s: %s, should be %s", c.a, c.b, s, c.patch)
+		}
+		if len(changes) != patch.Len() {
+			t.Fatalf("diff %s %s: %v changes", c.a, c.b, len(changes))
+		}
+		if a.UnsafeString() != c.a || b.UnsafeString() != c.b {
+			t.Fatalf("diff %s %s: modified", c.a, c.b)
+		}
+	}
+
+	_, changes := Diff(FromString(`{"a":[1,2],"b":"x"}`), FromString(`{"a":[1],"c":true}`))
+	want := []string{
+		`remove /b "x" <missing>`,
+		`remove /a/1 2 <missing>`,
+		`add /c <missing> true`,
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes: %v", changes)
+	}
+	for i, c := range changes {
+		old, new := c.Old.UnsafeString(), c.New.UnsafeString()
+		if !c.Old.Exists() {
+			old = "<missing>"
+		}
+		if !c.New.Exists() {
+			new = "<missing>"
+		}
+		if s := fmt.Sprintf("%v %v %v %v", c.Op, c.Path, old, new); s != want[i] {
+			t.Fatalf("change %v: %s, should be %s", i, s, want[i])
+		}
+	}
+}
+
+func TestDiffApply(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randArray := func() string {
+		n := rnd.Intn(8)
+		s := "["
+		for i, id := range rnd.Perm(10)[:n] {
+			if i > 0 {
+				s += ","
+			}
+			s += fmt.Sprintf(`{"id":%v,"v":%v}`, id, rnd.Intn(2))
+		}
+		return s + "]"
+	}
+	for i := 0; i < 500; i++ {
+		a := FromString(`{"items":` + randArray() + `}`)
+		b := FromString(`{"items":` + randArray() + `}`)
+		for _, arrays := range []ArrayDiff{ArrayByIndex, ArrayByLCS, ArrayByKey("id"), ArrayByKey("v")} {
+			patch, _ := Diff(a, b, arrays)
+			g := FromString(a.String())
+			if err := g.ApplyPatch(patch); err != nil {
+				t.Fatalf("apply %s to %s: %v", patch, a, err)
+			}
+			if !jsonEqual(g, b) {
+				t.Fatalf("apply %s to %s: %s, should be %s", patch, a, g, b)
+			}
+		}
+	}
+}
@@ -0,0 +1,484 @@
+package ejson
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// filterExpr是"[?(...)]"中的过滤表达式，对每个候选值求值。
+type filterExpr interface {
+	eval(c cursor) bool
+}
+
+// operand是过滤表达式中的操作数：以"@"开头的相对路径或字面量。
+type operand struct {
+	segs    []segment
+	literal []byte // 非空时为字面量
+}
+
+func (o *operand) value(c cursor) []byte {
+	if o.literal != nil {
+		return o.literal
+	}
+	return c.lookup(o.segs)
+}
+
+type filterOr struct{ left, right filterExpr }
+
+func (f *filterOr) eval(c cursor) bool { return f.left.eval(c) || f.right.eval(c) }
+
+type filterAnd struct{ left, right filterExpr }
+
+func (f *filterAnd) eval(c cursor) bool { return f.left.eval(c) && f.right.eval(c) }
+
+type filterNot struct{ x filterExpr }
+
+func (f *filterNot) eval(c cursor) bool { return !f.x.eval(c) }
+
+// filterExists：路径操作数判断值是否存在，字面量判断其bool值。
+type filterExists struct{ x *operand }
+
+func (f *filterExists) eval(c cursor) bool {
+	raw := f.x.value(c)
+	if f.x.literal != nil {
+		b, _ := (&JSON{raw: raw}).TryBool()
+		return b
+	}
+	return len(raw) > 0
+}
+
+type filterCompare struct {
+	op          string
+	left, right *operand
+}
+
+func (f *filterCompare) eval(c cursor) bool {
+	a := &JSON{raw: f.left.value(c)}
+	b := &JSON{raw: f.right.value(c)}
+	switch f.op {
+	case "==":
+		return filterEqual(a, b)
+	case "!=":
+		return !filterEqual(a, b)
+	}
+	n, ok := filterCompareValue(a, b)
+	if !ok {
+		return false
+	}
+	switch f.op {
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	}
+	return false
+}
+
+type filterMatch struct {
+	x  *operand
+	re *regexp.Regexp
+}
+
+func (f *filterMatch) eval(c cursor) bool {
+	raw := f.x.value(c)
+	if len(raw) == 0 {
+		return false
+	}
+	return f.re.MatchString((&JSON{raw: raw}).String())
+}
+
+func isRawNumber(raw []byte) bool {
+	return len(raw) > 0 && (raw[0] == '-' || ('0' <= raw[0] && raw[0] <= '9'))
+}
+
+func isRawBool(raw []byte) bool {
+	s := unsafeString(raw)
+	return s == "true" || s == "false"
+}
+
+// filterEqual判断a、b是否相等。任一方是数字或bool时，另一方按TryFloat、TryBool宽松转换后比较，
+// 如"1" == 1、"true" == true。
+func filterEqual(a, b *JSON) bool {
+	if !a.Exists() || !b.Exists() {
+		return a.Exists() == b.Exists()
+	}
+	if a.IsNull() || b.IsNull() {
+		return a.IsNull() && b.IsNull()
+	}
+	if isRawBool(a.raw) || isRawBool(b.raw) {
+		x, ok1 := a.TryBool()
+		y, ok2 := b.TryBool()
+		return ok1 && ok2 && x == y
+	}
+	if isRawNumber(a.raw) || isRawNumber(b.raw) {
+		n, ok := compareNumber(a, b)
+		return ok && n == 0
+	}
+	if a.IsStr() && b.IsStr() {
+		return a.Str() == b.Str()
+	}
+	return bytes.Equal(compactRaw(a.raw), compactRaw(b.raw))
+}
+
+// filterCompareValue比较a、b的大小：两者都能转换为数字时按数字比较，都是字符串时按字符串比较。
+func filterCompareValue(a, b *JSON) (int, bool) {
+	if n, ok := compareNumber(a, b); ok {
+		return n, true
+	}
+	if a.IsStr() && b.IsStr() {
+		return strings.Compare(a.Str(), b.Str()), true
+	}
+	return 0, false
+}
+
+func compareNumber(a, b *JSON) (int, bool) {
+	if a.IsInt() && b.IsInt() {
+		x, _ := a.TryInt()
+		y, _ := b.TryInt()
+		return compareOrdered(x, y), true
+	}
+	x, ok1 := a.TryFloat()
+	y, ok2 := b.TryFloat()
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	return compareOrdered(x, y), true
+}
+
+func compareOrdered[T int64 | float64](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// compactRaw去掉raw中字符串以外的空白，用于比较object、array是否相等。
+func compactRaw(raw []byte) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(raw); {
+		c := raw[i]
+		if c == '"' {
+			end, _ := scanString(raw, i)
+			buf.Write(raw[i:end])
+			i = end
+			continue
+		}
+		if !isSpace(c) {
+			buf.WriteByte(c)
+		}
+		i++
+	}
+	return buf.Bytes()
+}
+
+// lookup以只读方式查询c中segs对应的值。
+func (c cursor) lookup(segs []segment) []byte {
+	if c.node != nil {
+		return lookup(c.node, segs)
+	}
+	raw := c.raw
+	for _, s := range segs {
+		raw = s.lookup(raw)
+		if len(raw) == 0 {
+			return nil
+		}
+	}
+	return raw
+}
+
+// parseFilter解析"[?(expr)]"或"[?expr]"，p.pos指向'['。
+func (p *keyParser) parseFilter(start int) (segment, error) {
+	p.pos += 2 // "[?"
+	expr, err := p.parseOr(start)
+	if err != nil {
+		return segment{}, err
+	}
+	p.skipSpaces()
+	if p.peek() != ']' {
+		return segment{}, p.errorf(start, "missing ']' after filter")
+	}
+	p.pos++
+	return segment{kind: segFilter, filter: expr}, nil
+}
+
+func (p *keyParser) skipSpaces() {
+	for !p.eof() && isSpace(p.key[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *keyParser) consume(s string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.key[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *keyParser) parseOr(start int) (filterExpr, error) {
+	left, err := p.parseAnd(start)
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd(start)
+		if err != nil {
+			return nil, err
+		}
+		left = &filterOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *keyParser) parseAnd(start int) (filterExpr, error) {
+	left, err := p.parseUnary(start)
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary(start)
+		if err != nil {
+			return nil, err
+		}
+		left = &filterAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *keyParser) parseUnary(start int) (filterExpr, error) {
+	if p.consume("!") {
+		if p.peek() == '=' {
+			return nil, p.errorf(start, "unexpected '!='")
+		}
+		x, err := p.parseUnary(start)
+		if err != nil {
+			return nil, err
+		}
+		return &filterNot{x: x}, nil
+	}
+	if p.consume("(") {
+		x, err := p.parseOr(start)
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf(start, "missing ')' in filter")
+		}
+		return x, nil
+	}
+	return p.parseComparison(start)
+}
+
+var compareOps = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+func (p *keyParser) parseComparison(start int) (filterExpr, error) {
+	left, err := p.parseOperand(start)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	op := ""
+	for _, o := range compareOps {
+		if strings.HasPrefix(p.key[p.pos:], o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		return &filterExists{x: left}, nil
+	}
+	p.pos += len(op)
+
+	if op == "=~" {
+		re, err := p.parseRegexp(start)
+		if err != nil {
+			return nil, err
+		}
+		return &filterMatch{x: left, re: re}, nil
+	}
+
+	right, err := p.parseOperand(start)
+	if err != nil {
+		return nil, err
+	}
+	return &filterCompare{op: op, left: left, right: right}, nil
+}
+
+// filterKeyDelims是过滤表达式中未加引号的key的结束字符。
+const filterKeyDelims = " \t\r\n.[]()=!<>&|~,\\\""
+
+func (p *keyParser) parseOperand(start int) (*operand, error) {
+	p.skipSpaces()
+	if p.eof() {
+		return nil, p.errorf(start, "unexpected end of filter")
+	}
+
+	switch c := p.peek(); {
+	case c == '@':
+		p.pos++
+		segs, err := p.parseRelative(start)
+		if err != nil {
+			return nil, err
+		}
+		return &operand{segs: segs}, nil
+
+	case c == '"' || c == '\'':
+		s, err := p.parseString(start)
+		if err != nil {
+			return nil, err
+		}
+		raw, _ := marshal(s)
+		return &operand{literal: raw}, nil
+
+	case c == '-' || ('0' <= c && c <= '9'):
+		end, ok := scanNumber([]byte(p.key), p.pos)
+		if !ok {
+			return nil, p.errorf(start, "invalid number in filter")
+		}
+		raw := []byte(p.key[p.pos:end])
+		p.pos = end
+		return &operand{literal: raw}, nil
+	}
+
+	for _, lit := range []string{"true", "false", "null"} {
+		if strings.HasPrefix(p.key[p.pos:], lit) {
+			p.pos += len(lit)
+			return &operand{literal: []byte(lit)}, nil
+		}
+	}
+	return nil, p.errorf(start, "unexpected '%c' in filter", p.peek())
+}
+
+// parseRelative解析"@"之后的相对路径，如"@.a.b[0]"、`@."x.y"`。
+func (p *keyParser) parseRelative(start int) ([]segment, error) {
+	var segs []segment
+	for {
+		switch p.peek() {
+		case '.':
+			p.pos++
+			if p.peek() == '"' {
+				key, err := p.parseQuoted(start)
+				if err != nil {
+					return nil, err
+				}
+				segs = append(segs, segment{kind: segKey, key: key})
+				continue
+			}
+			var b strings.Builder
+			for !p.eof() && strings.IndexByte(filterKeyDelims, p.peek()) < 0 {
+				b.WriteByte(p.peek())
+				p.pos++
+			}
+			if b.Len() == 0 {
+				return nil, p.errorf(start, "missing key after '@.'")
+			}
+			segs = append(segs, keyOrFuncSegment(b.String()))
+
+		case '[':
+			r := strings.IndexByte(p.key[p.pos:], ']')
+			if r < 0 {
+				return nil, p.errorf(start, "missing ']'")
+			}
+			content := p.key[p.pos+1 : p.pos+r]
+			idx, err := strconv.Atoi(content)
+			if err != nil {
+				return nil, p.errorf(start, "invalid index %q in filter", content)
+			}
+			segs = append(segs, indexSegment(idx))
+			p.pos += r + 1
+
+		default:
+			return segs, nil
+		}
+	}
+}
+
+// parseString解析单引号或双引号括起的字符串，'\'转义下一个字符。
+func (p *keyParser) parseString(start int) (string, error) {
+	quote := p.peek()
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(start, "missing closing %c in filter", quote)
+		}
+		c := p.key[p.pos]
+		p.pos++
+		if c == quote {
+			return b.String(), nil
+		}
+		if c == '\\' {
+			if p.eof() {
+				return "", p.errorf(start, "missing closing %c in filter", quote)
+			}
+			c = p.key[p.pos]
+			p.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			case 'r':
+				c = '\r'
+			}
+		}
+		b.WriteByte(c)
+	}
+}
+
+// parseRegexp解析"/pattern/flags"形式或字符串形式的正则表达式，flags支持i、m、s。
+func (p *keyParser) parseRegexp(start int) (*regexp.Regexp, error) {
+	p.skipSpaces()
+	var expr, flags string
+	switch p.peek() {
+	case '/':
+		p.pos++
+		var b strings.Builder
+		for {
+			if p.eof() {
+				return nil, p.errorf(start, "missing closing '/' in filter")
+			}
+			c := p.key[p.pos]
+			p.pos++
+			if c == '/' {
+				break
+			}
+			if c == '\\' && p.peek() == '/' {
+				c = '/'
+				p.pos++
+			}
+			b.WriteByte(c)
+		}
+		expr = b.String()
+		for !p.eof() && strings.IndexByte("ims", p.peek()) >= 0 {
+			flags += string(p.peek())
+			p.pos++
+		}
+	case '"', '\'':
+		s, err := p.parseString(start)
+		if err != nil {
+			return nil, err
+		}
+		expr = s
+	default:
+		return nil, p.errorf(start, "missing regexp after '=~'")
+	}
+	if flags != "" {
+		expr = "(?" + flags + ")" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, p.errorf(start, "invalid regexp: %v", err)
+	}
+	return re, nil
+}
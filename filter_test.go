@@ -0,0 +1,83 @@
+package ejson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	g := FromString(`{"orders":[
+		{"id":1,"status":"active","price":10,"paid":true,"tags":["a"]},
+		{"id":2,"status":"closed","price":"25","paid":"false"},
+		{"id":3,"status":"Active","price":5.5,"paid":false,"user":{"name":"bob"}},
+		{"id":4,"status":"active","price":100,"paid":0,"x.y":1}
+	]}`)
+	cases := map[string]string{
+		`orders[?(@.status=="active")].id`:               `[1,4]`,
+		`orders[?(@.status=='active')].id`:               `[1,4]`,
+		`orders[?@.status!="active"].id`:                 `[2,3]`,
+		`orders[?(@.price>=10)].id`:                      `[1,2,4]`,
+		`orders[?(@.price<10)].id`:                       `[3]`,
+		`orders[?(@.price=="25")].id`:                    `[2]`,
+		`orders[?(@.price==25)].id`:                      `[2]`,
+		`orders[?(@.paid==false)].id`:                    `[2,3,4]`,
+		`orders[?(@.paid==true)].id`:                     `[1]`,
+		`orders[?(@.user)].id`:                           `[3]`,
+		`orders[?(!@.user)].id`:                          `[1,2,4]`,
+		`orders[?(@.user.name=="bob")].id`:               `[3]`,
+		`orders[?(@.tags[0]=="a")].id`:                   `[1]`,
+		`orders[?(@.status=~/^act/i)].id`:                `[1,3,4]`,
+		`orders[?(@.status=~/^act/)].id`:                 `[1,4]`,
+		`orders[?(@.status=="active" && @.price>50)].id`: `[4]`,
+		`orders[?(@.id==1 || @.id==3)].id`:               `[1,3]`,
+		`orders[?((@.id==1 || @.id==2) && !@.tags)].id`:  `[2]`,
+		`orders[?(@."x.y"==1)].id`:                       `[4]`,
+		`orders[?(@.price > @.id)].id`:                   `[1,2,3,4]`,
+		`orders[?(@.id>=2)][?(@.id<=3)]`:                 `[]`,
+		`..[?(@.name)].name`:                             `["bob"]`,
+		`orders[?(@.missing==null)].id`:                  `[]`,
+	}
+	for key, want := range cases {
+		if s := g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get %v: %s, should be %s", key, s, want)
+		}
+		if s := FromString(g.UnsafeString()).Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup %v: %s, should be %s", key, s, want)
+		}
+	}
+
+	if s := FromString(`{"a":{"x":{"v":1},"y":{"v":2}}}`).Get(`a[?(@.v>1)]`).UnsafeString(); s != `[{"v":2}]` {
+		t.Fatalf("object filter: %s", s)
+	}
+	if s := FromString(`{"a":"[1,2,3]"}`).Get(`a[?(@>1)]`).UnsafeString(); s != `[2,3]` {
+		t.Fatalf("str json filter: %s", s)
+	}
+}
+
+func TestFilterSet(t *testing.T) {
+	g := FromString(`{"orders":[{"id":1,"paid":true},{"id":2,"paid":false},{"id":3}]}`)
+	g.Get(`orders[?(@.paid==false)].flag`).Set(true)
+	if s := g.UnsafeString(); s != `{"orders":[{"id":1,"paid":true},{"id":2,"paid":false,"flag":true},{"id":3}]}` {
+		t.Fatalf("set: %s", s)
+	}
+	g.Get(`orders[?(!@.paid)]`).Remove()
+	if s := g.UnsafeString(); s != `{"orders":[{"id":1,"paid":true},{"id":2,"paid":false,"flag":true}]}` {
+		t.Fatalf("remove: %s", s)
+	}
+}
+
+func TestFilterInvalid(t *testing.T) {
+	for _, key := range []string{
+		`a[?(@.x==1]`, `a[?(@.x==)]`, `a[?(@.x=="1)]`, `a[?(@.x=~/[/)]`,
+		`a[?(@.x=~1)]`, `a[?(@.)]`, `a[?(#)]`, `a[?(@.x==1)`,
+	} {
+		_, err := parseSmartKey(key)
+		var pe *PathError
+		if !errors.As(err, &pe) {
+			t.Fatalf("%q should be invalid", key)
+		}
+		if pe.Segment != key[2:] && pe.Segment != key {
+			t.Fatalf("%q error segment: %q", key, pe.Segment)
+		}
+	}
+}
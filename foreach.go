@@ -0,0 +1,80 @@
+package ejson
+
+import "slices"
+
+// ForEachEntry按key顺序遍历object，fn返回false时停止遍历，不是object时不做任何操作。
+// 与Keys、ObjectIndex不同，遍历时不会复制key列表。
+//
+// 遍历过程中修改json的行为：
+//   - 插入到当前key之后的key会被遍历到，插入到当前key之前的key不会被遍历到；
+//   - 当前key仍存在时，从它的新位置之后继续，因此MoveKey、SortKeys等移动key后可能跳过或重复部分值；
+//   - 当前key被删除时，从调用fn前它的下一个key继续；下一个key也被删除时，从当前key原来的位置继续，
+//     此时如果当前key之前的值也被删除，会跳过部分值；
+//   - 当前object本身被Set替换时，停止遍历。
+func (g *JSON) ForEachEntry(fn func(key string, value *JSON) bool) {
+	if !g.IsObject() || !g.asObject() {
+		return
+	}
+	obj := g.object
+	for i := 0; i < len(obj.keys); i++ {
+		key := obj.keys[i]
+		next, hasNext := "", i+1 < len(obj.keys)
+		if hasNext {
+			next = obj.keys[i+1]
+		}
+		if !fn(key, obj.entry[key]) {
+			return
+		}
+		if g.object != obj {
+			return
+		}
+		if i >= len(obj.keys) || obj.keys[i] != key {
+			i = resumeIndex(obj.keys, key, next, hasNext, i)
+		}
+	}
+}
+
+// ForEachElem按顺序遍历array，fn返回false时停止遍历，不是array时不做任何操作。
+// 遍历过程中修改json的行为与ForEachEntry相同，i是值当前的下标。
+// 对Get返回的多值结果，按顺序遍历所有匹配值。
+func (g *JSON) ForEachElem(fn func(i int, value *JSON) bool) {
+	if g.results != nil {
+		g.results.Each(fn)
+		return
+	}
+	if !g.IsArray() || !g.asArray() {
+		return
+	}
+	arr := g.array
+	for i := 0; i < len(arr.values); i++ {
+		v := arr.values[i]
+		var next *JSON
+		hasNext := i+1 < len(arr.values)
+		if hasNext {
+			next = arr.values[i+1]
+		}
+		if !fn(i, v) {
+			return
+		}
+		if g.array != arr {
+			return
+		}
+		if i >= len(arr.values) || arr.values[i] != v {
+			i = resumeIndex(arr.values, v, next, hasNext, i)
+		}
+	}
+}
+
+// resumeIndex在遍历完cur后s被修改时，返回继续遍历的位置的前一个位置。
+// cur仍存在时从cur之后继续，否则从next继续，next也不存在时从cur原来的位置i继续。
+func resumeIndex[E comparable](s []E, cur, next E, hasNext bool, i int) int {
+	if j := slices.Index(s, cur); j >= 0 {
+		return j
+	}
+	if hasNext {
+		if j := slices.Index(s, next); j >= 0 {
+			return j - 1
+		}
+	}
+	return min(i, len(s)) - 1
+}
@@ -0,0 +1,217 @@
+package ejson
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	g := FromString(`{"a":1,"b":[1,2,3],"c":"x"}`)
+
+	var entries []string
+	g.ForEachEntry(func(key string, value *JSON) bool {
+		entries = append(entries, key+"="+value.String())
+		return true
+	})
+	if s := strings.Join(entries, ","); s != "a=1,b=[1,2,3],c=x" {
+		t.Fatalf("entries: %s", s)
+	}
+
+	var sum int64
+	g.Get("b").ForEachElem(func(i int, value *JSON) bool {
+		sum += int64(i) * value.Int()
+		return i < 1
+	})
+	if sum != 2 {
+		t.Fatalf("elems stop: %v", sum)
+	}
+
+	n := 0
+	g.ForEachElem(func(int, *JSON) bool { n++; return true })
+	g.Get("b").ForEachEntry(func(string, *JSON) bool { n++; return true })
+	g.Get("x").ForEachEntry(func(string, *JSON) bool { n++; return true })
+	if n != 0 {
+		t.Fatal("iterate wrong kind")
+	}
+
+	var ids []string
+	FromString(`[{"id":1},{"id":2}]`).Get("[*].id").ForEachElem(func(i int, value *JSON) bool {
+		ids = append(ids, fmt.Sprint(i, value))
+		return true
+	})
+	if s := strings.Join(ids, ","); s != "0 1,1 2" {
+		t.Fatalf("results: %s", s)
+	}
+}
+
+func TestForEachMutate(t *testing.T) {
+	// 删除当前值或之前的值，不会跳过或重复
+	g := FromString(`{"a":1,"b":2,"c":3,"d":4}`)
+	var keys []string
+	g.ForEachEntry(func(key string, value *JSON) bool {
+		keys = append(keys, key)
+		switch key {
+		case "a":
+			value.Remove()
+		case "b":
+			g.Get("a2").Set(0) // 追加
+		case "c":
+			g.Get("b").Remove()
+		}
+		return true
+	})
+	if s := strings.Join(keys, ","); s != "a,b,c,d,a2" {
+		t.Fatalf("mutate entries: %s", s)
+	}
+	if s := g.String(); s != `{"c":3,"d":4,"a2":0}` {
+		t.Fatalf("mutate entries json: %s", s)
+	}
+
+	a := FromString(`[1,2,3,4]`)
+	var seen []string
+	a.ForEachElem(func(i int, value *JSON) bool {
+		seen = append(seen, fmt.Sprint(i, ":", value))
+		if value.Int()%2 == 0 {
+			value.Remove()
+		}
+		return true
+	})
+	if s := strings.Join(seen, ","); s != "0:1,1:2,1:3,2:4" {
+		t.Fatalf("mutate elems: %s", s)
+	}
+	if s := a.String(); s != `[1,3]` {
+		t.Fatalf("mutate elems json: %s", s)
+	}
+
+	// 同时删除当前值和之前的值，从原本的下一个值继续
+	g = FromString(`{"a":1,"b":2,"c":3,"d":4}`)
+	keys = keys[:0]
+	g.ForEachEntry(func(key string, value *JSON) bool {
+		keys = append(keys, key)
+		if key == "b" {
+			g.Get("a").Remove()
+			value.Remove()
+		}
+		return true
+	})
+	if s := strings.Join(keys, ","); s != "a,b,c,d" {
+		t.Fatalf("remove current and previous entries: %s", s)
+	}
+
+	a = FromString(`[1,2,3,4]`)
+	seen = seen[:0]
+	a.ForEachElem(func(i int, value *JSON) bool {
+		seen = append(seen, fmt.Sprint(i, ":", value))
+		if value.Int() == 2 {
+			a.ArrayIndex(0).Remove()
+			value.Remove()
+		}
+		return true
+	})
+	if s := strings.Join(seen, ","); s != "0:1,1:2,0:3,1:4" {
+		t.Fatalf("remove current and previous elems: %s", s)
+	}
+
+	// 下一个值也被删除时，从当前值原来的位置继续
+	g = FromString(`{"a":1,"b":2,"c":3,"d":4}`)
+	keys = keys[:0]
+	g.ForEachEntry(func(key string, value *JSON) bool {
+		keys = append(keys, key)
+		if key == "b" {
+			value.Remove()
+			g.Get("c").Remove()
+			g.Get("e").Set(5)
+		}
+		return true
+	})
+	if s := strings.Join(keys, ","); s != "a,b,d,e" {
+		t.Fatalf("remove current and next entries: %s", s)
+	}
+
+	// 插入到当前值之前的值不会被遍历到，之后的值会被遍历到
+	g = FromString(`{"a":1,"b":2,"c":3}`)
+	keys = keys[:0]
+	g.ForEachEntry(func(key string, value *JSON) bool {
+		keys = append(keys, key)
+		if key == "b" {
+			g.InsertBefore("a", "before", 0)
+			g.InsertAfter("b", "after", 0)
+		}
+		return true
+	})
+	if s := strings.Join(keys, ","); s != "a,b,after,c" {
+		t.Fatalf("insert entries: %s", s)
+	}
+
+	a = FromString(`[1,2,3]`)
+	seen = seen[:0]
+	a.ForEachElem(func(i int, value *JSON) bool {
+		seen = append(seen, fmt.Sprint(i, ":", value))
+		if value.Int() == 2 {
+			a.Prepend(0)
+			a.Insert(3, 9)
+		}
+		return true
+	})
+	if s := strings.Join(seen, ","); s != "0:1,1:2,3:9,4:3" {
+		t.Fatalf("insert elems: %s", s)
+	}
+
+	// 替换整个object后停止
+	g = FromString(`{"a":1,"b":2}`)
+	n := 0
+	g.ForEachEntry(func(key string, value *JSON) bool {
+		n++
+		g.Set(map[string]int{"x": 1, "y": 2})
+		return true
+	})
+	if n != 1 {
+		t.Fatalf("replace object: %v", n)
+	}
+}
+
+func TestForEachHoles(t *testing.T) {
+	// 写入越界下标时跳过的位置是null，不会遍历到nil
+	g := FromString(`[]`)
+	g.Get("[2]").Set(1)
+	var seen []string
+	g.ForEachElem(func(i int, value *JSON) bool {
+		seen = append(seen, fmt.Sprint(i, ":", value.UnsafeString()))
+		return true
+	})
+	if s := strings.Join(seen, ","); s != "0:null,1:null,2:1" {
+		t.Fatalf("holes: %s", s)
+	}
+	if !g.ArrayIndex(1).IsNull() {
+		t.Fatal("hole should be null")
+	}
+	g.ArrayIndex(1).Set(2)
+	if s := g.String(); s != `[null,2,1]` {
+		t.Fatalf("set hole: %s", s)
+	}
+}
+
+func BenchmarkForEachEntry(b *testing.B) {
+	g := FromBytes(benchPayload(1000))
+	g.asObject()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		n := 0
+		g.ForEachEntry(func(string, *JSON) bool { n++; return true })
+	}
+}
+
+func BenchmarkKeysObjectIndex(b *testing.B) {
+	g := FromBytes(benchPayload(1000))
+	g.asObject()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		n := 0
+		for _, key := range g.Keys() {
+			if g.ObjectIndex(key) != nil {
+				n++
+			}
+		}
+	}
+}
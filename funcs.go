@@ -0,0 +1,194 @@
+package ejson
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// Func是smart key中的路径函数，如"items.#length"、"obj.@keys"。
+// js是函数前面的路径对应的值，前面的路径匹配多个值时，js是由所有匹配值组成的数组；
+// 返回值作为后续路径的输入，返回json树上的节点(如@first)时可以通过Get写入。
+// js可能不存在，返回不存在的值或nil表示没有结果。
+type Func func(js *JSON) *JSON
+
+var funcs = struct {
+	sync.RWMutex
+	m map[string]Func
+}{m: map[string]Func{
+	"length":  funcLength,
+	"keys":    funcKeys,
+	"reverse": funcReverse,
+	"first":   funcFirst,
+	"last":    funcLast,
+	"sum":     funcSum,
+}}
+
+// RegisterFunc注册路径函数，smart key中以"@name"或"#name"调用。name只能由字母、数字和'_'组成，
+// 否则panic；重复注册将替换之前的函数，包括内置函数。
+//
+// 内置函数：
+//   - length：string、array、object的长度，与Len相同；
+//   - keys：object的所有key；
+//   - reverse：倒序的array，或key倒序的object，结果是副本，其它值没有结果；
+//   - first、last：array的第一个、最后一个值；
+//   - sum：array中所有数字之和，数字字符串同样计入，忽略其它值。
+//
+// 已经解析的smart key(包括Compile返回的*Path)不受之后注册的函数影响。
+// 未注册的"@name"作为普通key，以'@'、'#'开头的key可以用双引号括起来，见QuoteKey。
+func RegisterFunc(name string, fn Func) {
+	if !validFuncName(name) {
+		panic(fmt.Sprintf("ejson: invalid func name %q", name))
+	}
+	if fn == nil {
+		panic("ejson: RegisterFunc fn is nil")
+	}
+	funcs.Lock()
+	funcs.m[name] = fn
+	funcs.Unlock()
+}
+
+func validFuncName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupFunc返回key对应的路径函数，key不是"@name"或"#name"形式或函数未注册时返回nil。
+func lookupFunc(key string) Func {
+	if len(key) < 2 || (key[0] != '@' && key[0] != '#') {
+		return nil
+	}
+	funcs.RLock()
+	fn := funcs.m[key[1:]]
+	funcs.RUnlock()
+	return fn
+}
+
+// keyOrFuncSegment将未加引号的key解析为路径函数或普通key。
+func keyOrFuncSegment(key string) segment {
+	if fn := lookupFunc(key); fn != nil {
+		return segment{kind: segFunc, key: key, fn: fn}
+	}
+	return keySegment(key)
+}
+
+// apply在j上调用路径函数。与其它片段一样，j是内容为object或array的字符串时先展开字符串；
+// 内容为数字等简单值的字符串不展开，如"@length"仍返回字符串长度。
+func (s segment) apply(j *JSON) *JSON {
+	if j.results == nil && isStrComposite(j.getRaw()) {
+		j = j.StrJSON()
+	}
+	if v := s.fn(j); v != nil {
+		return v
+	}
+	return new(JSON)
+}
+
+// call以只读方式在raw上调用路径函数，函数只能修改raw的独立副本。
+func (s segment) call(raw []byte) []byte {
+	if isStrComposite(raw) {
+		raw = strJSON(raw)
+	}
+	if v := s.fn(&JSON{raw: raw}); v != nil {
+		return v.getRaw()
+	}
+	return nil
+}
+
+func newValue(v any) *JSON {
+	js := new(JSON)
+	js.Set(v)
+	return js
+}
+
+func funcLength(js *JSON) *JSON {
+	if js.IsStr() || js.IsArray() || js.IsObject() {
+		return newValue(js.Len())
+	}
+	return new(JSON)
+}
+
+func funcKeys(js *JSON) *JSON {
+	if !js.IsObject() {
+		return new(JSON)
+	}
+	keys := js.Keys()
+	if keys == nil {
+		keys = []string{}
+	}
+	return newValue(keys)
+}
+
+func funcReverse(js *JSON) *JSON {
+	if js.IsArray() {
+		n := js.Len()
+		values := make([]*JSON, n)
+		for i := range values {
+			values[i] = js.ArrayIndex(n - 1 - i)
+		}
+		return newValue(values)
+	}
+	if js.IsObject() {
+		keys := js.Keys()
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for i := len(keys) - 1; i >= 0; i-- {
+			if i < len(keys)-1 {
+				buf.WriteByte(',')
+			}
+			key, _ := marshal(keys[i])
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(js.ObjectIndex(keys[i]).getRaw())
+		}
+		buf.WriteByte('}')
+		return &JSON{raw: buf.Bytes()}
+	}
+	return new(JSON)
+}
+
+func funcFirst(js *JSON) *JSON {
+	if !js.IsArray() || js.Len() == 0 {
+		return new(JSON)
+	}
+	return js.ArrayIndex(0)
+}
+
+func funcLast(js *JSON) *JSON {
+	if !js.IsArray() || js.Len() == 0 {
+		return new(JSON)
+	}
+	return js.ArrayIndex(js.Len() - 1)
+}
+
+func funcSum(js *JSON) *JSON {
+	if !js.IsArray() {
+		return new(JSON)
+	}
+	var isum int64
+	var fsum float64
+	float := false
+	for i, n := 0, js.Len(); i < n; i++ {
+		v := js.ArrayIndex(i)
+		if v.IsInt() {
+			x := v.Int()
+			isum += x
+			fsum += float64(x)
+		} else if x, ok := v.TryFloat(); ok {
+			fsum += x
+			float = true
+		}
+	}
+	if float {
+		return newValue(fsum)
+	}
+	return newValue(isum)
+}
@@ -0,0 +1,121 @@
+package ejson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFuncs(t *testing.T) {
+	g := FromString(`{"items":[{"id":1,"price":10},{"id":2,"price":"2.5"},{"id":3}],"obj":{"b":1,"a":2},"name":"ejson","list":[3,1,2],"@type":"x","s":"{\"k\":1,\"j\":2}","t":"[4,5]","n":"123"}`)
+	cases := map[string]string{
+		"items.#length":           `3`,
+		"items.@length":           `3`,
+		"name.#length":            `5`,
+		"obj.#length":             `2`,
+		"list[0].#length":         ``,
+		"obj.@keys":               `["b","a"]`,
+		"obj.@keys[1]":            `"a"`,
+		"list.@keys":              ``,
+		"list.@reverse":           `[2,1,3]`,
+		"obj.@reverse":            `{"a":2,"b":1}`,
+		"list.@first":             `3`,
+		"list.@last":              `2`,
+		"items.@last.id":          `3`,
+		"list.@sum":               `6`,
+		"items[*].price.@sum":     `12.5`,
+		"items[*].id.@sum":        `6`,
+		"items[*].#length":        `3`,
+		"items[*].id.@reverse":    `[3,2,1]`,
+		"items[*].id.@first":      `1`,
+		"items[1:].@first.id":     `2`,
+		"items[?@.id>1].#length":  `2`,
+		"missing.@first":          ``,
+		"@type":                   `"x"`,
+		`"#length"`:               ``,
+		"items[?@.#length==1].id": `[3]`,
+		"s.@keys":                 `["k","j"]`,
+		"s.@reverse":              `{"j":2,"k":1}`,
+		"t.@length":               `2`,
+		"t.@last":                 `5`,
+		"n.#length":               `3`,
+		"name.@reverse":           ``,
+		"list[0].@reverse":        ``,
+	}
+	for key, want := range cases {
+		if s := g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get %v: %s, should be %s", key, s, want)
+		}
+		if s := g.Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup %v: %s, should be %s", key, s, want)
+		}
+		if s := g.GetMany(key)[0].UnsafeString(); s != want {
+			t.Fatalf("get many %v: %s, should be %s", key, s, want)
+		}
+	}
+
+	if _, err := g.GetE("..@keys"); err == nil {
+		t.Fatal("func after '..' should be invalid")
+	}
+	if s := QuoteKey("#length"); s != `"#length"` {
+		t.Fatalf("quote func name: %s", s)
+	}
+}
+
+func TestFuncModify(t *testing.T) {
+	g := FromString(`{"list":[1,2,3],"items":[{"id":1},{"id":2}]}`)
+
+	g.Get("list.@first").Set(0)
+	g.Get("items[*].@last.ok").Set(true)
+	if s := g.String(); s != `{"list":[0,2,3],"items":[{"id":1},{"id":2,"ok":true}]}` {
+		t.Fatalf("set through func: %s", s)
+	}
+
+	// 字符串中的json同样可以通过路径函数写入
+	g = FromString(`{"list":[1,2,3],"items":[{"id":1},{"id":2,"ok":true}],"s":"[1,2]"}`)
+	g.Get("s.@first").Set(0)
+	if s := g.Get("s").Str(); s != `[0,2]` {
+		t.Fatalf("set through func in string: %s", s)
+	}
+	g.Get("s").Remove()
+	g.Get("list[0]").Set(0)
+
+	// 计算结果是独立的值，写入不影响json树
+	g.Get("list.@reverse[0]").Set(100)
+	MustCompile("list.@last").Remove(g)
+	if s := g.String(); s != `{"list":[0,2],"items":[{"id":1},{"id":2,"ok":true}]}` {
+		t.Fatalf("modify func result: %s", s)
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	RegisterFunc("upper", func(js *JSON) *JSON {
+		if !js.IsStr() {
+			return nil
+		}
+		v := new(JSON)
+		v.Set(strings.ToUpper(js.Str()))
+		return v
+	})
+
+	g := FromString(`{"name":"ejson","users":[{"name":"a"}]}`)
+	if s := g.Get("name.@upper").Str(); s != "EJSON" {
+		t.Fatalf("upper: %s", s)
+	}
+	if s := g.Lookup("users[0].name.#upper").Str(); s != "A" {
+		t.Fatalf("upper: %s", s)
+	}
+	if g.Get("users.@upper").Exists() {
+		t.Fatal("upper on array should not exists")
+	}
+
+	for _, name := range []string{"", "a-b", "@x"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("register %q should panic", name)
+				}
+			}()
+			RegisterFunc(name, funcFirst)
+		}()
+	}
+}
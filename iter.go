@@ -0,0 +1,21 @@
+//go:build go1.23
+
+package ejson
+
+import "iter"
+
+// Entries返回按key顺序遍历object的迭代器，不是object时不产生任何值。
+// 遍历过程中修改json的行为见ForEachEntry。
+//
+//	for key, value := range js.Entries() {
+//		fmt.Println(key, value)
+//	}
+func (g *JSON) Entries() iter.Seq2[string, *JSON] {
+	return g.ForEachEntry
+}
+
+// Elems返回按顺序遍历array的迭代器，不是array时不产生任何值。
+// 遍历过程中修改json的行为见ForEachEntry。
+func (g *JSON) Elems() iter.Seq2[int, *JSON] {
+	return g.ForEachElem
+}
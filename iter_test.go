@@ -0,0 +1,34 @@
+//go:build go1.23
+
+package ejson
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIter(t *testing.T) {
+	g := FromString(`{"a":1,"b":[1,2,3]}`)
+
+	var keys []string
+	for key, value := range g.Entries() {
+		keys = append(keys, key)
+		if key == "b" {
+			for i, v := range value.Elems() {
+				if int64(i+1) != v.Int() {
+					t.Fatalf("elem %v: %v", i, v)
+				}
+				if i == 1 {
+					break
+				}
+			}
+		}
+	}
+	if s := strings.Join(keys, ","); s != "a,b" {
+		t.Fatalf("entries: %s", s)
+	}
+
+	for range g.Elems() {
+		t.Fatal("object should have no elems")
+	}
+}
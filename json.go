@@ -3,6 +3,7 @@ package ejson
 import (
 	"bytes"
 	"encoding/json"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -13,6 +14,9 @@ type JSON struct {
 	str    *strjson
 	parent *JSON
 	update updateFuncs
+
+	results *Results // 非空时代表Get返回的结果集
+	match   KeyMatch // key匹配方式，见SetKeyMatch
 }
 
 type updateFuncs []func()
@@ -42,6 +46,9 @@ func FromString(s string) *JSON {
 
 // Len返回string、array、object长度，其它类型值返回0
 func (g *JSON) Len() int {
+	if g.results != nil {
+		return g.results.Len()
+	}
 	if g.IsNull() {
 		return 0
 	}
@@ -62,6 +69,14 @@ func (g *JSON) Len() int {
 // ArrayIndex返回array第i个值。如果不存在，返回*JSON不为空。
 // 当返回的*JSON被写入数据后，返回的*JSON将写入当前array。
 func (g *JSON) ArrayIndex(i int) *JSON {
+	if g.results != nil {
+		return g.results.Index(i)
+	}
+	return g.pendingArray().Index(i)
+}
+
+// pendingArray返回g的array。g不是array时返回新的array，写入它的值后g将变为该array。
+func (g *JSON) pendingArray() *array {
 	g.asArray()
 	a := g.array
 	if a == nil {
@@ -77,12 +92,17 @@ func (g *JSON) ArrayIndex(i int) *JSON {
 			}
 		})
 	}
-	return a.Index(i)
+	return a
 }
 
 // ObjectIndex返回object[key]的值。如果不存在，返回*JSON不为空。
 // 当返回的*JSON被写入数据后，返回的*JSON将写入当前object。
+// ObjectIndex总是精确匹配key，不受SetKeyMatch影响；需要按匹配方式查找时使用Get。
+// 对Get返回的多值结果，与Get一样返回每个匹配值中key对应的值组成的结果集。
 func (g *JSON) ObjectIndex(key string) *JSON {
+	if g.results != nil {
+		return g.results.each(func(js *JSON) *JSON { return js.ObjectIndex(key) })
+	}
 	g.asObject()
 	obj := g.object
 	if obj == nil {
@@ -103,7 +123,11 @@ func (g *JSON) ObjectIndex(key string) *JSON {
 
 // StrJSON返回字符串的值。如果不存在，返回*JSON不为空。
 // 当返回的*JSON被写入数据后，返回的*JSON将写入当前字符串。
+// 对Get返回的多值结果，返回每个匹配值中字符串的值组成的结果集。
 func (g *JSON) StrJSON() *JSON {
+	if g.results != nil {
+		return g.results.each((*JSON).StrJSON)
+	}
 	g.asStr()
 	str := g.str
 	if str == nil {
@@ -124,6 +148,10 @@ func (g *JSON) StrJSON() *JSON {
 
 // Remove将当前*JSON从json树中移除
 func (g *JSON) Remove() {
+	if g.results != nil {
+		g.results.Remove()
+		return
+	}
 	if g.parent == nil {
 		return
 	}
@@ -160,6 +188,9 @@ func (g *JSON) UnsafeString() string {
 
 // Exists返回当前*JSON是否存在。
 func (g *JSON) Exists() bool {
+	if g.results != nil {
+		return g.results.Exists()
+	}
 	return len(g.getRaw()) > 0
 }
 
@@ -188,6 +219,11 @@ func (g *JSON) MarshalJSON() ([]byte, error) {
 }
 
 func (g *JSON) getRaw() []byte {
+	if g.results != nil {
+		// 匹配值可能随时被修改，不能缓存
+		raw, _ := g.results.MarshalJSON()
+		return raw
+	}
 	if len(g.raw) == 0 {
 		if g.array != nil {
 			g.raw, _ = g.array.MarshalJSON()
@@ -212,6 +248,12 @@ func (g *JSON) Set(v any) error {
 }
 
 func (g *JSON) reset(raw json.RawMessage) {
+	if g.results != nil {
+		for _, js := range g.results.nodes {
+			js.reset(raw)
+		}
+		return
+	}
 	if bytes.Equal(g.getRaw(), raw) {
 		return
 	}
@@ -238,14 +280,63 @@ func (g *JSON) reset(raw json.RawMessage) {
 	g.update.run()
 }
 
+// modified在直接修改g的object或array后调用，和reset一样清除所有祖先缓存的raw。
+func (g *JSON) modified() {
+	g.raw = nil
+	for p := g.parent; p != nil; p = p.parent {
+		p.raw = nil
+	}
+	g.update.run()
+}
+
 // Get支持类型'[0].object.key.array[0][1].key'式取值。
+// smartKey不合法时返回的*JSON不存在，严格模式下将panic，见SetStrict。
+//
+// smartKey中的"*"或"[*]"匹配object或array的所有值，如"items[*].id"、"users.*.email"。
+// "[+]"、"[-]"表示array末尾之后、开头之前的位置，读取时不存在，写入时追加到末尾或插入到开头，如"items[+]"。
+// "[start:end:step]"按Python切片语法匹配array中的一段值，如"items[1:4]"、"items[-3:]"、"items[::2]"。
+// "..key"匹配任意深度的key，如"..id"、"a..price"，内容为json的字符串同样会展开查找。
+// "[?(expr)]"匹配object或array中满足expr的值，如`orders[?(@.paid==false && @.price>=10)]`，
+// expr支持== != < <= > >=比较、&& || !、存在判断(@.key)和正则匹配(@.name=~/^a/i)，
+// 比较时按TryInt、TryFloat、TryBool宽松转换，如"1" == 1。
+// 以上情况返回的*JSON代表所有匹配值：读取时是由匹配值组成的数组，ArrayIndex返回第i个匹配值，
+// Set、Remove作用于每个匹配值。需要逐个处理匹配值时可以使用GetAll。
+// "(a|b)"匹配第一个存在的key，如"data.(err_code|errcode|code)"，都不存在时Set写入第一个key。
+// "@name"或"#name"调用路径函数，如"items.#length"、"obj.@keys"、"items[*].price.@sum"，见RegisterFunc。
 func (g *JSON) Get(smartKey string) *JSON {
-	es, err := parseSmartKey(smartKey)
-	if err != nil {
+	segs, ok := parseKey(smartKey)
+	if !ok {
 		return new(JSON)
 	}
-	for _, e := range es {
-		g = e(g)
+	return get(g, segs)
+}
+
+// GetE与Get相同，但smartKey不合法时返回*PathError。
+func (g *JSON) GetE(smartKey string) (*JSON, error) {
+	segs, err := parseSmartKey(smartKey)
+	if err != nil {
+		return new(JSON), err
+	}
+	return get(g, segs), nil
+}
+
+var strict atomic.Bool
+
+// SetStrict设置是否开启严格模式，默认关闭。
+// 严格模式下，Get、Lookup、GetMany遇到不合法的smart key时直接panic，
+// 而不是返回不存在的值，便于在测试中发现smart key拼写错误。
+func SetStrict(on bool) {
+	strict.Store(on)
+}
+
+// parseKey解析smartKey，严格模式下smartKey不合法将panic。
+func parseKey(smartKey string) ([]segment, bool) {
+	segs, err := parseSmartKey(smartKey)
+	if err != nil {
+		if strict.Load() {
+			panic(err)
+		}
+		return nil, false
 	}
-	return g
+	return segs, true
 }
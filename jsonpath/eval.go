@@ -0,0 +1,399 @@
+package jsonpath
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/eachain/ejson"
+)
+
+type context struct {
+	root  *ejson.JSON
+	paths bool // 是否生成规范化路径，过滤表达式中的子查询不需要
+}
+
+func (ctx *context) query(start Node, segs []segment) NodeList {
+	nodes := NodeList{start}
+	for _, seg := range segs {
+		var next NodeList
+		for _, n := range nodes {
+			if seg.descendant {
+				next = ctx.descend(n, seg.selectors, next)
+				continue
+			}
+			for _, sel := range seg.selectors {
+				next = sel.apply(ctx, n, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// descend按文档顺序在n及其所有后代上应用selectors。
+func (ctx *context) descend(n Node, sels []selector, dst NodeList) NodeList {
+	for _, sel := range sels {
+		dst = sel.apply(ctx, n, dst)
+	}
+	ctx.children(n, func(c Node) {
+		dst = ctx.descend(c, sels, dst)
+	})
+	return dst
+}
+
+type kind int
+
+const (
+	kindNothing kind = iota
+	kindNull
+	kindBool
+	kindNumber
+	kindString
+	kindArray
+	kindObject
+)
+
+func kindOf(v *ejson.JSON) kind {
+	if v == nil {
+		return kindNothing
+	}
+	raw := v.UnsafeString()
+	if raw == "" {
+		return kindNothing
+	}
+	switch c := raw[0]; {
+	case c == 'n':
+		return kindNull
+	case c == 't' || c == 'f':
+		return kindBool
+	case c == '"':
+		return kindString
+	case c == '[':
+		return kindArray
+	case c == '{':
+		return kindObject
+	}
+	return kindNumber
+}
+
+func (ctx *context) child(n Node, name string, v *ejson.JSON) Node {
+	c := Node{Value: v}
+	if ctx.paths {
+		c.Path = n.Path + "[" + quoteName(name) + "]"
+	}
+	return c
+}
+
+func (ctx *context) elem(n Node, i int, v *ejson.JSON) Node {
+	c := Node{Value: v}
+	if ctx.paths {
+		c.Path = n.Path + "[" + strconv.Itoa(i) + "]"
+	}
+	return c
+}
+
+// children按顺序遍历n的所有子节点。
+func (ctx *context) children(n Node, fn func(c Node)) {
+	switch kindOf(n.Value) {
+	case kindArray:
+		for i, l := 0, n.Value.Len(); i < l; i++ {
+			fn(ctx.elem(n, i, n.Value.ArrayIndex(i)))
+		}
+	case kindObject:
+		for _, key := range n.Value.Keys() {
+			fn(ctx.child(n, key, n.Value.ObjectIndex(key)))
+		}
+	}
+}
+
+func (s nameSelector) apply(ctx *context, n Node, dst NodeList) NodeList {
+	if kindOf(n.Value) != kindObject {
+		return dst
+	}
+	if v := n.Value.ObjectIndex(s.name); v.Exists() {
+		dst = append(dst, ctx.child(n, s.name, v))
+	}
+	return dst
+}
+
+func (wildcardSelector) apply(ctx *context, n Node, dst NodeList) NodeList {
+	ctx.children(n, func(c Node) {
+		dst = append(dst, c)
+	})
+	return dst
+}
+
+func (s indexSelector) apply(ctx *context, n Node, dst NodeList) NodeList {
+	if kindOf(n.Value) != kindArray {
+		return dst
+	}
+	l := n.Value.Len()
+	i := s.index
+	if i < 0 {
+		i += l
+	}
+	if 0 <= i && i < l {
+		dst = append(dst, ctx.elem(n, i, n.Value.ArrayIndex(i)))
+	}
+	return dst
+}
+
+// bounds按RFC 9535 2.3.4.2.2计算切片的下界和上界。
+func (s sliceSelector) bounds(l int) (lower, upper int) {
+	normalize := func(i int) int {
+		if i >= 0 {
+			return i
+		}
+		return l + i
+	}
+	clamp := func(i, lo, hi int) int {
+		return min(max(i, lo), hi)
+	}
+
+	if s.step >= 0 {
+		start, end := 0, l
+		if s.start != nil {
+			start = normalize(*s.start)
+		}
+		if s.end != nil {
+			end = normalize(*s.end)
+		}
+		return clamp(start, 0, l), clamp(end, 0, l)
+	}
+
+	start, end := l-1, -l-1
+	if s.start != nil {
+		start = normalize(*s.start)
+	}
+	if s.end != nil {
+		end = normalize(*s.end)
+	}
+	return clamp(end, -1, l-1), clamp(start, -1, l-1)
+}
+
+func (s sliceSelector) apply(ctx *context, n Node, dst NodeList) NodeList {
+	if kindOf(n.Value) != kindArray || s.step == 0 {
+		return dst
+	}
+	lower, upper := s.bounds(n.Value.Len())
+	if s.step > 0 {
+		for i := lower; i < upper; i += s.step {
+			dst = append(dst, ctx.elem(n, i, n.Value.ArrayIndex(i)))
+		}
+	} else {
+		for i := upper; lower < i; i += s.step {
+			dst = append(dst, ctx.elem(n, i, n.Value.ArrayIndex(i)))
+		}
+	}
+	return dst
+}
+
+func (s filterSelector) apply(ctx *context, n Node, dst NodeList) NodeList {
+	ctx.children(n, func(c Node) {
+		if s.expr.test(ctx, c.Value) {
+			dst = append(dst, c)
+		}
+	})
+	return dst
+}
+
+func (x *orExpr) test(ctx *context, cur *ejson.JSON) bool {
+	for _, e := range x.exprs {
+		if e.test(ctx, cur) {
+			return true
+		}
+	}
+	return false
+}
+
+func (x *andExpr) test(ctx *context, cur *ejson.JSON) bool {
+	for _, e := range x.exprs {
+		if !e.test(ctx, cur) {
+			return false
+		}
+	}
+	return true
+}
+
+func (x *notExpr) test(ctx *context, cur *ejson.JSON) bool {
+	return !x.x.test(ctx, cur)
+}
+
+func (x *existsExpr) test(ctx *context, cur *ejson.JSON) bool {
+	return len(x.q.nodes(ctx, cur)) > 0
+}
+
+func (x *funcTest) test(ctx *context, cur *ejson.JSON) bool {
+	switch v := x.f.call(ctx, cur).(type) {
+	case bool:
+		return v
+	case NodeList:
+		return len(v) > 0
+	}
+	return false
+}
+
+func (x *compareExpr) test(ctx *context, cur *ejson.JSON) bool {
+	a := x.left.value(ctx, cur)
+	b := x.right.value(ctx, cur)
+	switch x.op {
+	case "==":
+		return equal(a, b)
+	case "!=":
+		return !equal(a, b)
+	case "<":
+		return less(a, b)
+	case "<=":
+		return less(a, b) || equal(a, b)
+	case ">":
+		return less(b, a)
+	case ">=":
+		return less(b, a) || equal(a, b)
+	}
+	return false
+}
+
+func (x *literalExpr) value(*context, *ejson.JSON) *ejson.JSON {
+	return x.v
+}
+
+func (q *queryExpr) nodes(ctx *context, cur *ejson.JSON) NodeList {
+	sub := &context{root: ctx.root}
+	start := cur
+	if q.abs {
+		start = ctx.root
+	}
+	return sub.query(Node{Value: start}, q.segs)
+}
+
+func (q *queryExpr) value(ctx *context, cur *ejson.JSON) *ejson.JSON {
+	nodes := q.nodes(ctx, cur)
+	if len(nodes) != 1 {
+		return nil
+	}
+	return nodes[0].Value
+}
+
+func (f *funcExpr) value(ctx *context, cur *ejson.JSON) *ejson.JSON {
+	v, _ := f.call(ctx, cur).(*ejson.JSON)
+	return v
+}
+
+func (f *funcExpr) call(ctx *context, cur *ejson.JSON) any {
+	args := make([]any, len(f.args))
+	for i, arg := range f.args {
+		switch f.fn.params[i] {
+		case valueType:
+			args[i] = arg.(operandExpr).value(ctx, cur)
+		case nodesType:
+			switch arg := arg.(type) {
+			case *queryExpr:
+				args[i] = arg.nodes(ctx, cur)
+			case *funcExpr:
+				args[i] = arg.call(ctx, cur)
+			}
+		case logicalType:
+			args[i] = arg.(logicalExpr).test(ctx, cur)
+		}
+	}
+	if f.re != nil {
+		args[1] = f.re
+	}
+	return f.fn.call(args)
+}
+
+func number(v *ejson.JSON) float64 {
+	f, _ := strconv.ParseFloat(v.UnsafeString(), 64)
+	return f
+}
+
+// equal按RFC 9535 2.3.5.2.2比较a、b是否相等，nil表示Nothing。
+func equal(a, b *ejson.JSON) bool {
+	ka, kb := kindOf(a), kindOf(b)
+	if ka != kb {
+		return false
+	}
+	switch ka {
+	case kindNothing, kindNull:
+		return true
+	case kindBool:
+		return a.UnsafeString() == b.UnsafeString()
+	case kindNumber:
+		return number(a) == number(b)
+	case kindString:
+		return a.Str() == b.Str()
+	case kindArray:
+		l := a.Len()
+		if l != b.Len() {
+			return false
+		}
+		for i := 0; i < l; i++ {
+			if !equal(a.ArrayIndex(i), b.ArrayIndex(i)) {
+				return false
+			}
+		}
+		return true
+	case kindObject:
+		keys := a.Keys()
+		if len(keys) != b.Len() {
+			return false
+		}
+		for _, key := range keys {
+			bv := b.ObjectIndex(key)
+			if !bv.Exists() || !equal(a.ObjectIndex(key), bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// less只比较同为数字或同为字符串的值。
+func less(a, b *ejson.JSON) bool {
+	ka, kb := kindOf(a), kindOf(b)
+	if ka != kb {
+		return false
+	}
+	switch ka {
+	case kindNumber:
+		return number(a) < number(b)
+	case kindString:
+		return a.Str() < b.Str()
+	}
+	return false
+}
+
+// quoteName按RFC 9535 2.7将name转为规范化路径中的字符串。
+func quoteName(name string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range name {
+		switch r {
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			if r < 0x20 {
+				b.WriteString(`\u00`)
+				b.WriteByte("0123456789abcdef"[r>>4])
+				b.WriteByte("0123456789abcdef"[r&0xF])
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
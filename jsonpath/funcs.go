@@ -0,0 +1,162 @@
+package jsonpath
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eachain/ejson"
+)
+
+// paramType是RFC 9535 2.4.1定义的函数参数和结果类型。
+type paramType int
+
+const (
+	valueType paramType = iota
+	logicalType
+	nodesType
+)
+
+// function是函数扩展，call的参数和返回值按类型分别为：
+// ValueType: *ejson.JSON(nil表示Nothing)，LogicalType: bool，NodesType: NodeList。
+type function struct {
+	params []paramType
+	result paramType
+	call   func(args []any) any
+}
+
+var functions = map[string]*function{
+	"length": {params: []paramType{valueType}, result: valueType, call: length},
+	"count":  {params: []paramType{nodesType}, result: valueType, call: count},
+	"match":  {params: []paramType{valueType, valueType}, result: logicalType, call: match},
+	"search": {params: []paramType{valueType, valueType}, result: logicalType, call: search},
+	"value":  {params: []paramType{nodesType}, result: valueType, call: value},
+}
+
+func intValue(n int) *ejson.JSON {
+	v := new(ejson.JSON)
+	v.Set(n)
+	return v
+}
+
+func length(args []any) any {
+	v, _ := args[0].(*ejson.JSON)
+	switch kindOf(v) {
+	case kindString:
+		return intValue(utf8.RuneCountInString(v.Str()))
+	case kindArray, kindObject:
+		return intValue(v.Len())
+	}
+	return (*ejson.JSON)(nil)
+}
+
+func count(args []any) any {
+	nodes, _ := args[0].(NodeList)
+	return intValue(len(nodes))
+}
+
+func value(args []any) any {
+	nodes, _ := args[0].(NodeList)
+	if len(nodes) != 1 {
+		return (*ejson.JSON)(nil)
+	}
+	return nodes[0].Value
+}
+
+func match(args []any) any {
+	return regexpFunc(args, true)
+}
+
+func search(args []any) any {
+	return regexpFunc(args, false)
+}
+
+// regexpFunc实现match、search。args[1]是解析时已编译的字面量正则(*regexp.Regexp)，
+// 或查询得到的值；后者来自json文档本身，每次调用时编译，不缓存，以免文档内容使缓存无限增长。
+func regexpFunc(args []any, full bool) bool {
+	s, _ := args[0].(*ejson.JSON)
+	if kindOf(s) != kindString {
+		return false
+	}
+	re, _ := args[1].(*regexp.Regexp)
+	if re == nil {
+		expr, _ := args[1].(*ejson.JSON)
+		if kindOf(expr) != kindString {
+			return false
+		}
+		if re = compileIRegexp(expr.Str(), full); re == nil {
+			return false
+		}
+	}
+	return re.MatchString(s.Str())
+}
+
+// compileIRegexp将RFC 9485 I-Regexp转为Go正则表达式，不合法时返回nil。
+// full为true时整串匹配(match)，否则子串匹配(search)。
+func compileIRegexp(expr string, full bool) *regexp.Regexp {
+	goexpr, ok := translateIRegexp(expr)
+	if !ok {
+		return nil
+	}
+	if full {
+		goexpr = `\A(?:` + goexpr + `)\z`
+	}
+	re, err := regexp.Compile(goexpr)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// translateIRegexp处理I-Regexp与Go正则表达式的差异：
+// '.'不匹配'\n'、'\r'；'^'、'$'在字符类之外是普通字符；
+// 只支持单字符转义和\p{..}、\P{..}，不支持\d、\w等多字符转义和"(?"扩展语法。
+func translateIRegexp(expr string) (string, bool) {
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(expr) {
+				return "", false
+			}
+			e := expr[i+1]
+			switch {
+			case e == 'p' || e == 'P':
+			case strings.IndexByte(`\()*+-.?[]^{|}nrt`, e) >= 0:
+			default:
+				return "", false
+			}
+			b.WriteByte(c)
+			b.WriteByte(e)
+			i++
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+			b.WriteByte(c)
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+			// 字符类开头的"^"和"]"不结束字符类
+			if i+1 < len(expr) && expr[i+1] == '^' {
+				b.WriteByte('^')
+				i++
+			}
+		case c == '.':
+			b.WriteString(`[^\n\r]`)
+		case c == '^' || c == '$':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '(' && i+1 < len(expr) && expr[i+1] == '?':
+			return "", false
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if inClass {
+		return "", false
+	}
+	return b.String(), true
+}
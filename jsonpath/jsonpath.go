@@ -0,0 +1,116 @@
+// Package jsonpath按RFC 9535的语法和语义实现JSONPath，在*ejson.JSON上查询。
+// 官方JSONPath Compliance Test Suite尚未放入仓库，放到testdata/cts后由TestCompliance运行。
+//
+// 查询结果中的每个节点都是json树上的节点，可以直接读取或修改：
+//
+//	js := ejson.FromString(`{"store":{"book":[{"price":8},{"price":12}]}}`)
+//	nodes, _ := jsonpath.Query(js, "$.store.book[?@.price<10]")
+//	nodes.Set(0)
+//	// {"store":{"book":[0,{"price":12}]}}
+package jsonpath
+
+import (
+	"fmt"
+
+	"github.com/eachain/ejson"
+)
+
+// Node是查询结果中的一个节点。
+type Node struct {
+	Path  string      // RFC 9535规范化路径，如$['store']['book'][0]
+	Value *ejson.JSON // json树上的节点
+}
+
+// NodeList是查询结果，节点顺序与RFC 9535一致。
+type NodeList []Node
+
+// Values返回所有节点的值。
+func (nl NodeList) Values() []*ejson.JSON {
+	vals := make([]*ejson.JSON, len(nl))
+	for i, n := range nl {
+		vals[i] = n.Value
+	}
+	return vals
+}
+
+// Paths返回所有节点的规范化路径。
+func (nl NodeList) Paths() []string {
+	paths := make([]string, len(nl))
+	for i, n := range nl {
+		paths[i] = n.Path
+	}
+	return paths
+}
+
+// Set将每个节点的值设置为v。
+func (nl NodeList) Set(v any) error {
+	for _, n := range nl {
+		if err := n.Value.Set(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove将每个节点从json树中移除。
+func (nl NodeList) Remove() {
+	for _, n := range nl {
+		n.Value.Remove()
+	}
+}
+
+// SyntaxError表示JSONPath表达式不合法。
+type SyntaxError struct {
+	Expr   string // 完整的表达式
+	Offset int    // 出错位置的字节偏移
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("jsonpath: invalid expression %q at offset %v: %v", e.Expr, e.Offset, e.Reason)
+}
+
+// Path是解析后的JSONPath表达式，可以在多个goroutine间共享。
+type Path struct {
+	expr string
+	segs []segment
+}
+
+// Parse解析JSONPath表达式。
+func Parse(expr string) (*Path, error) {
+	p := &parser{src: expr}
+	segs, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return &Path{expr: expr, segs: segs}, nil
+}
+
+// MustParse与Parse相同，但表达式不合法时panic。
+func MustParse(expr string) *Path {
+	p, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String返回解析前的表达式。
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Query返回js中匹配p的所有节点。
+func (p *Path) Query(js *ejson.JSON) NodeList {
+	ctx := &context{root: js, paths: true}
+	return ctx.query(Node{Path: "$", Value: js}, p.segs)
+}
+
+// Query解析expr并返回js中匹配的所有节点。
+func Query(js *ejson.JSON, expr string) (NodeList, error) {
+	p, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Query(js), nil
+}
@@ -0,0 +1,190 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/eachain/ejson"
+)
+
+// testCase沿用JSONPath Compliance Test Suite(cts.json)的格式。
+// testdata/cases.json是手写的用例(RFC 9535中的示例和各语法的用例)，不是官方的测试套件。
+type testCase struct {
+	Name            string            `json:"name"`
+	Selector        string            `json:"selector"`
+	Document        json.RawMessage   `json:"document"`
+	Result          json.RawMessage   `json:"result"`
+	Results         []json.RawMessage `json:"results"`
+	ResultPaths     []string          `json:"result_paths"`
+	InvalidSelector bool              `json:"invalid_selector"`
+}
+
+func TestCases(t *testing.T) {
+	data, err := os.ReadFile("testdata/cases.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runSuite(t, data, nil)
+}
+
+// ctsSkips是官方测试套件中有意跳过的用例，key是用例名称，value是原因。
+var ctsSkips = map[string]string{}
+
+// TestCompliance运行官方的JSONPath Compliance Test Suite，需要将上游的cts.json和LICENSE放到testdata/cts目录：
+//
+//	curl -o testdata/cts/cts.json https://raw.githubusercontent.com/jsonpath-standard/jsonpath-compliance-test-suite/main/cts.json
+//	curl -o testdata/cts/LICENSE https://raw.githubusercontent.com/jsonpath-standard/jsonpath-compliance-test-suite/main/LICENSE
+func TestCompliance(t *testing.T) {
+	data, err := os.ReadFile("testdata/cts/cts.json")
+	if errors.Is(err, os.ErrNotExist) {
+		t.Skip("testdata/cts/cts.json is not vendored")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	runSuite(t, data, ctsSkips)
+}
+
+// runSuite按cts.json的格式运行data中的用例，skips中的用例将被跳过。
+func runSuite(t *testing.T, data []byte, skips map[string]string) {
+	var suite struct {
+		Tests []testCase `json:"tests"`
+	}
+	if err := json.Unmarshal(data, &suite); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range suite.Tests {
+		t.Run(c.Name, func(t *testing.T) {
+			if reason, ok := skips[c.Name]; ok {
+				t.Skip(reason)
+			}
+			p, err := Parse(c.Selector)
+			if c.InvalidSelector {
+				if err == nil {
+					t.Fatalf("%q should be invalid", c.Selector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse %q: %v", c.Selector, err)
+			}
+
+			nodes := p.Query(ejson.FromBytes(c.Document))
+			got := decode(t, nodes)
+			wants := c.Results
+			if c.Result != nil {
+				wants = []json.RawMessage{c.Result}
+			}
+			matched := false
+			for _, want := range wants {
+				var v any
+				if err := json.Unmarshal(want, &v); err != nil {
+					t.Fatal(err)
+				}
+				if reflect.DeepEqual(got, v) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				t.Fatalf("query %q: %v, should be one of %s", c.Selector, got, wants)
+			}
+			if c.ResultPaths != nil && !reflect.DeepEqual(nodes.Paths(), c.ResultPaths) {
+				t.Fatalf("query %q paths: %q, should be %q", c.Selector, nodes.Paths(), c.ResultPaths)
+			}
+		})
+	}
+}
+
+func decode(t *testing.T, nodes NodeList) any {
+	b, err := json.Marshal(nodes.Values())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v any
+	if err = json.Unmarshal(b, &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestSyntaxError(t *testing.T) {
+	_, err := Parse("$.a[?@.b=1]")
+	var se *SyntaxError
+	if !errors.As(err, &se) {
+		t.Fatalf("error type: %T", err)
+	}
+	if se.Expr != "$.a[?@.b=1]" || se.Offset != 8 {
+		t.Fatalf("syntax error: %+v", se)
+	}
+}
+
+func TestNodeListModify(t *testing.T) {
+	js := ejson.FromString(`{"store":{"book":[{"price":8},{"price":12},{"price":9}]}}`)
+
+	nodes, err := Query(js, "$.store.book[?@.price<10].price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = nodes.Set(10); err != nil {
+		t.Fatal(err)
+	}
+	if s := js.String(); s != `{"store":{"book":[{"price":10},{"price":12},{"price":10}]}}` {
+		t.Fatalf("set: %s", s)
+	}
+
+	MustParse("$.store.book[?@.price>10]").Query(js).Remove()
+	if s := js.String(); s != `{"store":{"book":[{"price":10},{"price":10}]}}` {
+		t.Fatalf("remove: %s", s)
+	}
+
+	MustParse("$..price").Query(js)[1].Value.Set("free")
+	if s := js.String(); s != `{"store":{"book":[{"price":10},{"price":"free"}]}}` {
+		t.Fatalf("set node: %s", s)
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	js := ejson.FromString(`[{"a":"abc","p":"a.c"},{"a":"abc","p":"b"},{"a":"xbz","p":"[a-z]+"}]`)
+	cases := map[string]string{
+		`$[?match(@.a, "a.c")].a`: `["abc","abc"]`,
+		`$[?search(@.a, "b")].a`:  `["abc","abc","xbz"]`,
+		`$[?match(@.a, @.p)].p`:   `["a.c","[a-z]+"]`,
+		`$[?search(@.a, @.p)].p`:  `["a.c","b","[a-z]+"]`,
+		`$[?match(@.a, "(")]`:     `[]`,
+	}
+	for expr, want := range cases {
+		got, err := json.Marshal(MustParse(expr).Query(js).Values())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("query %s: %s, should be %s", expr, got, want)
+		}
+	}
+}
+
+func TestKeyMatch(t *testing.T) {
+	// RFC 9535的name selector总是精确匹配，不受SetKeyMatch影响
+	js := ejson.FromString(`{"A":{"user_id":1},"a":{"userId":2}}`)
+	js.SetKeyMatch(ejson.MatchNormalize)
+	cases := map[string]string{
+		`$.a.user_id`: `[]`,
+		`$.a.userId`:  `[2]`,
+		`$.A.user_id`: `[1]`,
+		`$["B"]`:      `[]`,
+	}
+	for expr, want := range cases {
+		got, err := json.Marshal(MustParse(expr).Query(js).Values())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("query %s: %s, should be %s", expr, got, want)
+		}
+	}
+}
@@ -0,0 +1,761 @@
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/eachain/ejson"
+)
+
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+type selector interface {
+	apply(ctx *context, n Node, dst NodeList) NodeList
+}
+
+type nameSelector struct{ name string }
+
+type wildcardSelector struct{}
+
+type indexSelector struct{ index int }
+
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+type filterSelector struct{ expr logicalExpr }
+
+// logicalExpr是过滤表达式中结果为LogicalType的表达式。
+type logicalExpr interface {
+	test(ctx *context, cur *ejson.JSON) bool
+}
+
+type orExpr struct{ exprs []logicalExpr }
+
+type andExpr struct{ exprs []logicalExpr }
+
+type notExpr struct{ x logicalExpr }
+
+// existsExpr是以查询作为test-expr，查询结果非空时为真。
+type existsExpr struct{ q *queryExpr }
+
+// funcTest是以函数作为test-expr，函数结果为LogicalType或NodesType。
+type funcTest struct{ f *funcExpr }
+
+type compareExpr struct {
+	op          string
+	left, right operandExpr
+}
+
+// operandExpr是比较表达式的操作数，值为nil时表示Nothing。
+type operandExpr interface {
+	value(ctx *context, cur *ejson.JSON) *ejson.JSON
+}
+
+type literalExpr struct{ v *ejson.JSON }
+
+type queryExpr struct {
+	abs  bool // 以"$"开头
+	segs []segment
+}
+
+type funcExpr struct {
+	name string
+	fn   *function
+	args []any          // literalExpr、queryExpr、funcExpr或logicalExpr
+	re   *regexp.Regexp // match、search的正则是字符串字面量时，解析时编译
+}
+
+const maxInt = 1<<53 - 1
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Expr: p.src, Offset: p.pos, Reason: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func (p *parser) skipBlank() {
+	for !p.eof() && isBlank(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) expect(c byte) error {
+	if p.peek() != c {
+		if p.eof() {
+			return p.errorf("expected '%c', got end of expression", c)
+		}
+		return p.errorf("expected '%c', got '%c'", c, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) parseQuery() ([]segment, error) {
+	if err := p.expect('$'); err != nil {
+		return nil, err
+	}
+	segs, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected '%c'", p.peek())
+	}
+	return segs, nil
+}
+
+func (p *parser) parseSegments() ([]segment, error) {
+	var segs []segment
+	for {
+		save := p.pos
+		p.skipBlank()
+		var seg segment
+		var err error
+		switch {
+		case p.hasPrefix(".."):
+			p.pos += 2
+			seg, err = p.parseDotted()
+			seg.descendant = true
+		case p.peek() == '.':
+			p.pos++
+			if p.peek() == '[' {
+				return nil, p.errorf("unexpected '['")
+			}
+			seg, err = p.parseDotted()
+		case p.peek() == '[':
+			seg, err = p.parseBracketed()
+		default:
+			p.pos = save
+			return segs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, seg)
+	}
+}
+
+// parseDotted解析'.'或".."之后的"*"、member-name-shorthand，".."之后还可以是bracketed-selection。
+func (p *parser) parseDotted() (segment, error) {
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		return segment{selectors: []selector{wildcardSelector{}}}, nil
+	case c == '[':
+		return p.parseBracketed()
+	}
+	name, err := p.parseMemberName()
+	if err != nil {
+		return segment{}, err
+	}
+	return segment{selectors: []selector{nameSelector{name}}}, nil
+}
+
+func isNameFirst(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || r == '_' ||
+		(0x80 <= r && r <= 0xD7FF) || (0xE000 <= r && r <= 0x10FFFF)
+}
+
+func (p *parser) parseMemberName() (string, error) {
+	start := p.pos
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if r == utf8.RuneError && size <= 1 {
+			return "", p.errorf("invalid utf-8")
+		}
+		if !isNameFirst(r) && !(p.pos > start && '0' <= r && r <= '9') {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		if p.eof() {
+			return "", p.errorf("missing member name")
+		}
+		return "", p.errorf("unexpected '%c'", p.peek())
+	}
+	return p.src[start:p.pos], nil
+}
+
+func (p *parser) parseBracketed() (segment, error) {
+	p.pos++ // '['
+	var seg segment
+	for {
+		p.skipBlank()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return segment{}, err
+		}
+		seg.selectors = append(seg.selectors, sel)
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return seg, nil
+		}
+		if err := p.expect(','); err != nil {
+			return segment{}, err
+		}
+	}
+}
+
+func (p *parser) parseSelector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector{s}, nil
+	case c == '*':
+		p.pos++
+		return wildcardSelector{}, nil
+	case c == '?':
+		p.pos++
+		p.skipBlank()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr}, nil
+	}
+
+	var start, end *int
+	if p.peek() != ':' {
+		i, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		p.skipBlank()
+		if p.peek() != ':' {
+			return indexSelector{i}, nil
+		}
+		start = &i
+	}
+	p.pos++ // ':'
+	p.skipBlank()
+	if c := p.peek(); c == '-' || ('0' <= c && c <= '9') {
+		i, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		end = &i
+		p.skipBlank()
+	}
+	step := 1
+	if p.peek() == ':' {
+		p.pos++
+		p.skipBlank()
+		if c := p.peek(); c == '-' || ('0' <= c && c <= '9') {
+			i, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			step = i
+		}
+	}
+	return sliceSelector{start: start, end: end, step: step}, nil
+}
+
+// parseInt解析int = "0" / (["-"] DIGIT1 *DIGIT)，取值范围为I-JSON整数。
+func (p *parser) parseInt() (int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	switch c := p.peek(); {
+	case c == '0':
+		if p.pos > start {
+			return 0, p.errorf("invalid integer '-0'")
+		}
+		p.pos++
+	case '1' <= c && c <= '9':
+		for !p.eof() && '0' <= p.peek() && p.peek() <= '9' {
+			p.pos++
+		}
+	default:
+		if p.eof() {
+			return 0, p.errorf("expected integer, got end of expression")
+		}
+		return 0, p.errorf("unexpected '%c'", c)
+	}
+	i, err := strconv.ParseInt(p.src[start:p.pos], 10, 64)
+	if err != nil || i > maxInt || i < -maxInt {
+		p.pos = start
+		return 0, p.errorf("integer out of range")
+	}
+	return int(i), nil
+}
+
+// parseString解析单引号或双引号括起的字符串字面量。
+func (p *parser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("missing closing %c", quote)
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c < 0x20:
+			return "", p.errorf("invalid control character in string")
+		case c != '\\':
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		p.pos++ // '\\'
+		c = p.peek()
+		p.pos++
+		switch c {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '/', '\\':
+			b.WriteByte(c)
+		case 'u':
+			r, err := p.parseUnicodeEscape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			if c == quote {
+				b.WriteByte(c)
+				continue
+			}
+			p.pos--
+			return "", p.errorf("invalid escape")
+		}
+	}
+}
+
+func (p *parser) parseHex4() (rune, error) {
+	if len(p.src)-p.pos < 4 {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	v, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	p.pos += 4
+	return rune(v), nil
+}
+
+func (p *parser) parseUnicodeEscape() (rune, error) {
+	r, err := p.parseHex4()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case 0xDC00 <= r && r <= 0xDFFF:
+		return 0, p.errorf("invalid unicode escape: lone low surrogate")
+	case 0xD800 <= r && r <= 0xDBFF:
+		if !p.hasPrefix(`\u`) {
+			return 0, p.errorf("invalid unicode escape: lone high surrogate")
+		}
+		p.pos += 2
+		r2, err := p.parseHex4()
+		if err != nil {
+			return 0, err
+		}
+		if r2 < 0xDC00 || r2 > 0xDFFF {
+			return 0, p.errorf("invalid unicode escape: invalid low surrogate")
+		}
+		return utf16.DecodeRune(r, r2), nil
+	}
+	return r, nil
+}
+
+func (p *parser) parseOr() (logicalExpr, error) {
+	var exprs []logicalExpr
+	for {
+		x, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, x)
+		save := p.pos
+		p.skipBlank()
+		if !p.hasPrefix("||") {
+			p.pos = save
+			break
+		}
+		p.pos += 2
+		p.skipBlank()
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &orExpr{exprs}, nil
+}
+
+func (p *parser) parseAnd() (logicalExpr, error) {
+	var exprs []logicalExpr
+	for {
+		x, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, x)
+		save := p.pos
+		p.skipBlank()
+		if !p.hasPrefix("&&") {
+			p.pos = save
+			break
+		}
+		p.pos += 2
+		p.skipBlank()
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &andExpr{exprs}, nil
+}
+
+func (p *parser) parseParen() (logicalExpr, error) {
+	p.pos++ // '('
+	p.skipBlank()
+	x, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// parseBasic解析basic-expr = paren-expr / comparison-expr / test-expr。
+func (p *parser) parseBasic() (logicalExpr, error) {
+	if p.peek() == '!' {
+		p.pos++
+		p.skipBlank()
+		if p.peek() == '(' {
+			x, err := p.parseParen()
+			if err != nil {
+				return nil, err
+			}
+			return &notExpr{x}, nil
+		}
+		x, err := p.parseTest()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{x}, nil
+	}
+	if p.peek() == '(' {
+		return p.parseParen()
+	}
+
+	start := p.pos
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	save := p.pos
+	p.skipBlank()
+	op := p.parseCompareOp()
+	if op == "" {
+		p.pos = save
+		return p.testExpr(start, left)
+	}
+
+	l, err := p.operand(start, left)
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	start = p.pos
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.operand(start, right)
+	if err != nil {
+		return nil, err
+	}
+	return &compareExpr{op: op, left: l, right: r}, nil
+}
+
+func (p *parser) parseTest() (logicalExpr, error) {
+	start := p.pos
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	return p.testExpr(start, x)
+}
+
+// testExpr检查x是否可以作为test-expr：查询或结果为LogicalType、NodesType的函数。
+func (p *parser) testExpr(start int, x any) (logicalExpr, error) {
+	switch x := x.(type) {
+	case *queryExpr:
+		return &existsExpr{x}, nil
+	case *funcExpr:
+		if x.fn.result == valueType {
+			p.pos = start
+			return nil, p.errorf("function %v() result must be compared", x.name)
+		}
+		return &funcTest{x}, nil
+	}
+	p.pos = start
+	return nil, p.errorf("literal is not a valid test expression")
+}
+
+// operand检查x是否可以作为比较操作数：字面量、单值查询或结果为ValueType的函数。
+func (p *parser) operand(start int, x any) (operandExpr, error) {
+	switch x := x.(type) {
+	case *literalExpr:
+		return x, nil
+	case *queryExpr:
+		if !x.singular() {
+			p.pos = start
+			return nil, p.errorf("non-singular query is not comparable")
+		}
+		return x, nil
+	case *funcExpr:
+		if x.fn.result != valueType {
+			p.pos = start
+			return nil, p.errorf("function %v() result is not comparable", x.name)
+		}
+		return x, nil
+	}
+	p.pos = start
+	return nil, p.errorf("invalid comparable")
+}
+
+var compareOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *parser) parseCompareOp() string {
+	for _, op := range compareOps {
+		if p.hasPrefix(op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// parsePrimary解析字面量、查询或函数调用，返回*literalExpr、*queryExpr或*funcExpr。
+func (p *parser) parsePrimary() (any, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segs, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return &queryExpr{abs: c == '$', segs: segs}, nil
+
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		v := new(ejson.JSON)
+		v.Set(s)
+		return &literalExpr{v}, nil
+
+	case c == '-' || ('0' <= c && c <= '9'):
+		return p.parseNumber()
+
+	case 'a' <= c && c <= 'z':
+		start := p.pos
+		for !p.eof() {
+			c := p.peek()
+			if !('a' <= c && c <= 'z') && c != '_' && !('0' <= c && c <= '9') {
+				break
+			}
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		if p.peek() == '(' {
+			return p.parseFunc(start, name)
+		}
+		switch name {
+		case "true", "false", "null":
+			return &literalExpr{ejson.FromString(name)}, nil
+		}
+		p.pos = start
+		return nil, p.errorf("unexpected %q", name)
+	}
+
+	if p.eof() {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected '%c'", p.peek())
+}
+
+// parseNumber解析number = (int / "-0") [ frac ] [ exp ]。
+func (p *parser) parseNumber() (*literalExpr, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	switch c := p.peek(); {
+	case c == '0':
+		p.pos++
+	case '1' <= c && c <= '9':
+		for !p.eof() && '0' <= p.peek() && p.peek() <= '9' {
+			p.pos++
+		}
+	default:
+		return nil, p.errorf("invalid number")
+	}
+	if p.peek() == '.' {
+		p.pos++
+		if !('0' <= p.peek() && p.peek() <= '9') {
+			return nil, p.errorf("invalid number")
+		}
+		for !p.eof() && '0' <= p.peek() && p.peek() <= '9' {
+			p.pos++
+		}
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		if !('0' <= p.peek() && p.peek() <= '9') {
+			return nil, p.errorf("invalid number")
+		}
+		for !p.eof() && '0' <= p.peek() && p.peek() <= '9' {
+			p.pos++
+		}
+	}
+	return &literalExpr{ejson.FromString(p.src[start:p.pos])}, nil
+}
+
+func (p *parser) parseFunc(start int, name string) (*funcExpr, error) {
+	fn := functions[name]
+	if fn == nil {
+		p.pos = start
+		return nil, p.errorf("unknown function %v()", name)
+	}
+	f := &funcExpr{name: name, fn: fn}
+	p.pos++ // '('
+	p.skipBlank()
+	for i, typ := range fn.params {
+		if i > 0 {
+			p.skipBlank()
+			if err := p.expect(','); err != nil {
+				return nil, p.errorf("function %v() requires %v arguments", name, len(fn.params))
+			}
+			p.skipBlank()
+		}
+		arg, err := p.parseArg(name, typ)
+		if err != nil {
+			return nil, err
+		}
+		f.args = append(f.args, arg)
+	}
+	p.skipBlank()
+	if p.peek() != ')' {
+		return nil, p.errorf("function %v() requires %v arguments", name, len(fn.params))
+	}
+	p.pos++
+	if name == "match" || name == "search" {
+		if lit, ok := f.args[1].(*literalExpr); ok && lit.v.IsStr() {
+			f.re = compileIRegexp(lit.v.Str(), name == "match")
+		}
+	}
+	return f, nil
+}
+
+// parseArg按参数类型解析函数参数，检查参数是否well-typed。
+func (p *parser) parseArg(name string, typ paramType) (any, error) {
+	start := p.pos
+	if typ == logicalType {
+		return p.parseOr()
+	}
+
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case valueType:
+		switch x := x.(type) {
+		case *literalExpr:
+			return x, nil
+		case *queryExpr:
+			if x.singular() {
+				return x, nil
+			}
+		case *funcExpr:
+			if x.fn.result == valueType {
+				return x, nil
+			}
+		}
+	case nodesType:
+		switch x := x.(type) {
+		case *queryExpr:
+			return x, nil
+		case *funcExpr:
+			if x.fn.result == nodesType {
+				return x, nil
+			}
+		}
+	}
+	p.pos = start
+	return nil, p.errorf("invalid argument type for function %v()", name)
+}
+
+// singular判断q是否是单值查询，即只包含name、index选择器且不含".."。
+func (q *queryExpr) singular() bool {
+	for _, seg := range q.segs {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
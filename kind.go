@@ -0,0 +1,123 @@
+package ejson
+
+import "bytes"
+
+// Kind是json值的类型，由原始json决定，如`"1"`是String而不是Number。
+type Kind uint8
+
+const (
+	Missing Kind = iota // 值不存在
+	Null
+	Bool
+	Number
+	String
+	Array
+	Object
+	Invalid // 不是合法的json值
+)
+
+var kindNames = [...]string{
+	Missing: "missing",
+	Null:    "null",
+	Bool:    "bool",
+	Number:  "number",
+	String:  "string",
+	Array:   "array",
+	Object:  "object",
+	Invalid: "invalid",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "invalid"
+}
+
+// Kind返回当前*JSON原始json的类型，结果与IsNull、IsBool等一致。
+// null、true、false和数字校验整个值，不合法时返回Invalid；字符串、array、object只检查第一个字符，不校验整个值。
+func (g *JSON) Kind() Kind {
+	return kindOf(g.getRaw())
+}
+
+func kindOf(raw []byte) Kind {
+	if len(raw) == 0 {
+		return Missing
+	}
+	switch c := raw[0]; {
+	case c == 'n':
+		if unsafeString(raw) == "null" {
+			return Null
+		}
+	case c == 't' || c == 'f':
+		if s := unsafeString(raw); s == "true" || s == "false" {
+			return Bool
+		}
+	case c == '"':
+		return String
+	case c == '[':
+		return Array
+	case c == '{':
+		return Object
+	case c == '-' || ('0' <= c && c <= '9'):
+		if n, ok := scanNumber(raw, 0); ok && n == len(raw) {
+			return Number
+		}
+	}
+	return Invalid
+}
+
+// jsonEqual判断a、b是否是相同的json值：object不考虑key的顺序，数字按数值比较，如1和1.0相等。
+// 与filterEqual不同，不同类型的值总是不相等。
+func jsonEqual(a, b *JSON) bool {
+	// 多值结果按匹配值组成的数组比较，不能在结果上展开
+	if a.results != nil {
+		a = &JSON{raw: a.getRaw()}
+	}
+	if b.results != nil {
+		b = &JSON{raw: b.getRaw()}
+	}
+	ka, kb := a.Kind(), b.Kind()
+	if ka != kb {
+		return false
+	}
+	switch ka {
+	case Bool:
+		return a.getRaw()[0] == b.getRaw()[0]
+	case Number:
+		if bytes.Equal(a.getRaw(), b.getRaw()) {
+			return true
+		}
+		if a.IsInt() && b.IsInt() {
+			return a.Int() == b.Int()
+		}
+		if a.IsUint() && b.IsUint() {
+			return a.Uint() == b.Uint()
+		}
+		return a.Float() == b.Float()
+	case String:
+		return bytes.Equal(a.getRaw(), b.getRaw()) || a.Str() == b.Str()
+	case Array:
+		if !a.asArray() || !b.asArray() || len(a.array.values) != len(b.array.values) {
+			return false
+		}
+		for i, v := range a.array.values {
+			if !jsonEqual(v, b.array.values[i]) {
+				return false
+			}
+		}
+		return true
+	case Object:
+		if !a.asObject() || !b.asObject() || len(a.object.entry) != len(b.object.entry) {
+			return false
+		}
+		for key, v := range a.object.entry {
+			w := b.object.entry[key]
+			if w == nil || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return ka != Invalid
+}
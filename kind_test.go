@@ -0,0 +1,73 @@
+package ejson
+
+import "testing"
+
+func TestKind(t *testing.T) {
+	cases := map[string]Kind{
+		``:        Missing,
+		`null`:    Null,
+		`true`:    Bool,
+		`false`:   Bool,
+		`0`:       Number,
+		`-1.5e3`:  Number,
+		`"1"`:     String,
+		`[1]`:     Array,
+		` {} `:    Object,
+		`x`:       Invalid,
+		`Infinit`: Invalid,
+		`nope`:    Invalid,
+		`tru`:     Invalid,
+		`falsey`:  Invalid,
+		`1x`:      Invalid,
+		`-`:       Invalid,
+		`01`:      Invalid,
+	}
+	for raw, want := range cases {
+		if k := FromString(raw).Kind(); k != want {
+			t.Fatalf("kind of %q: %v, should be %v", raw, k, want)
+		}
+	}
+
+	g := FromString(`{"a":[1],"s":"{}"}`)
+	if k := g.Get("a[*]").Kind(); k != Array {
+		t.Fatalf("kind of results: %v", k)
+	}
+	if k := g.Get("x").Kind(); k != Missing {
+		t.Fatalf("kind of missing: %v", k)
+	}
+	if k := g.Get("s").Kind(); k != String {
+		t.Fatalf("kind of string json: %v", k)
+	}
+	if s := Kind(100).String(); s != "invalid" {
+		t.Fatalf("kind string: %v", s)
+	}
+}
+
+func TestJSONEqual(t *testing.T) {
+	equal := [][2]string{
+		{`{"a":1,"b":[1,"x"]}`, `{"b":[1,"x"],"a":1}`},
+		{`1`, `1.0`},
+		{`10`, `1e1`},
+		{`"A"`, `"A"`},
+		{`null`, `null`},
+		{`[]`, `[]`},
+	}
+	for _, c := range equal {
+		if !jsonEqual(FromString(c[0]), FromString(c[1])) {
+			t.Fatalf("%s should equal %s", c[0], c[1])
+		}
+	}
+	unequal := [][2]string{
+		{`{"a":1}`, `{"a":1,"b":2}`},
+		{`[1,2]`, `[2,1]`},
+		{`1`, `"1"`},
+		{`true`, `false`},
+		{`null`, ``},
+		{`9007199254740993`, `9007199254740992`},
+	}
+	for _, c := range unequal {
+		if jsonEqual(FromString(c[0]), FromString(c[1])) {
+			t.Fatalf("%s should not equal %s", c[0], c[1])
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package ejson
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Lookup以只读方式查询smartKey对应的值，语法与Get相同。
+// Lookup直接在原始json上扫描，跳过无关的兄弟节点，不会在json树上创建节点。
+// 返回的*JSON是独立的视图，对它的写入不会影响当前json树。
+func (g *JSON) Lookup(smartKey string) *JSON {
+	segs, ok := parseKey(smartKey)
+	if !ok {
+		return new(JSON)
+	}
+	return lookupJSON(g, segs)
+}
+
+// lookupJSON返回segs对应值的只读视图，segs包含多值片段时返回由匹配值组成的数组。
+func lookupJSON(g *JSON, segs []segment) *JSON {
+	if g.results == nil && !fanout(segs) {
+		return &JSON{raw: lookup(g, segs)}
+	}
+	curs, multi := lookupNodes(g, segs)
+	if !multi {
+		return &JSON{raw: curs[0].value()}
+	}
+	return &JSON{raw: cursorsRaw(curs)}
+}
+
+// cursorsRaw返回由curs组成的数组，不存在的值以null表示。
+func cursorsRaw(curs []cursor) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, c := range curs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if raw := c.value(); len(raw) > 0 {
+			buf.Write(raw)
+		} else {
+			buf.WriteString("null")
+		}
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+// cursor指向查询过程中的一个值：node非空时是已展开的json树节点，否则是原始json。
+// 两者都为空时表示值不存在。
+type cursor struct {
+	node *JSON
+	raw  []byte
+}
+
+func (c cursor) value() []byte {
+	if c.node != nil {
+		return c.node.getRaw()
+	}
+	return c.raw
+}
+
+func (c cursor) exists() bool {
+	return len(c.value()) > 0
+}
+
+// lookupCursors与getAll相同，但不会在json树上创建节点，不存在的值以空cursor表示。
+func lookupCursors(g *JSON, segs []segment) []cursor {
+	curs, _ := lookupNodes(g, segs)
+	return curs
+}
+
+// lookupNodes与getNodes相同，但不会在json树上创建节点。
+func lookupNodes(g *JSON, segs []segment) (curs []cursor, multi bool) {
+	segs = withMatch(segs, g.keyMatch())
+	curs = []cursor{{node: g}}
+	if g.results != nil {
+		curs = curs[:0]
+		for _, n := range g.results.nodes {
+			curs = append(curs, cursor{node: n})
+		}
+		multi = true
+	}
+	for _, s := range segs {
+		if s.kind == segFunc {
+			in := curs[0].value()
+			if multi {
+				in = cursorsRaw(curs)
+			}
+			curs, multi = []cursor{{raw: s.call(in)}}, false
+			continue
+		}
+		multi = multi || s.fanout()
+		next := make([]cursor, 0, len(curs))
+		for _, c := range curs {
+			next = s.lookupAll(next, c)
+		}
+		curs = next
+	}
+	return curs, multi
+}
+
+// lookupAll将s在c中匹配到的所有值追加到dst。
+func (s segment) lookupAll(dst []cursor, c cursor) []cursor {
+	if s.descend {
+		return s.lookupDescendants(dst, c)
+	}
+	node := c.node
+	if node != nil && node.str != nil {
+		node = node.str.value
+	}
+	if node != nil && (node.object != nil || node.array != nil) {
+		if !s.fanout() {
+			return append(dst, cursor{node: s.child(node)})
+		}
+		if node.array != nil {
+			children := make([]cursor, len(node.array.values))
+			for i, v := range node.array.values {
+				children[i] = cursor{node: v}
+			}
+			return appendAccepted(dst, s, children, true)
+		}
+		if s.kind == segSlice {
+			return dst
+		}
+		for _, key := range node.object.keys {
+			if c := (cursor{node: node.object.entry[key]}); s.accept(c) {
+				dst = append(dst, c)
+			}
+		}
+		return dst
+	}
+
+	raw := c.raw
+	if node != nil {
+		raw = node.getRaw()
+		s = s.withMatch(node.keyMatch())
+	}
+	if !s.fanout() {
+		return append(dst, cursor{raw: s.lookup(raw)})
+	}
+	raw = strJSON(raw)
+	if len(raw) == 0 {
+		return dst
+	}
+	switch raw[0] {
+	case '[':
+		var children []cursor
+		if !scanArray(raw, func(_ int, val []byte) bool {
+			children = append(children, cursor{raw: val})
+			return true
+		}) {
+			return dst
+		}
+		if s.kind == segSlice {
+			return appendSliced(dst, s, children)
+		}
+		for _, c := range children {
+			if s.accept(c) {
+				dst = append(dst, c)
+			}
+		}
+	case '{':
+		if s.kind == segSlice {
+			return dst
+		}
+		scanObjectDedup(raw, func(_ string, val []byte) {
+			if c := (cursor{raw: val}); s.accept(c) {
+				dst = append(dst, c)
+			}
+		})
+	}
+	return dst
+}
+
+// scanObjectDedup按key首次出现的顺序遍历object，key重复时以最后一个值为准，与asObject一致。
+func scanObjectDedup(raw []byte, fn func(key string, val []byte)) bool {
+	var keys []string
+	var vals map[string][]byte
+	if !scanObject(raw, func(rawKey, val []byte) bool {
+		key, ok := unquoteKey(rawKey)
+		if !ok {
+			return false
+		}
+		if vals == nil {
+			vals = make(map[string][]byte)
+		}
+		if _, ok := vals[key]; !ok {
+			keys = append(keys, key)
+		}
+		vals[key] = val
+		return true
+	}) {
+		return false
+	}
+	for _, key := range keys {
+		fn(key, vals[key])
+	}
+	return true
+}
+
+// lookup优先沿已展开的json树查找，遇到未展开的节点后改为扫描原始json。
+func lookup(g *JSON, segs []segment) []byte {
+	node := g
+	var raw []byte
+	m := MatchExact // 改为扫描原始json时生效的key匹配方式
+	for _, s := range segs {
+		if s.kind == segFunc {
+			if node != nil {
+				raw = node.getRaw()
+				node = nil
+			}
+			raw = s.call(raw)
+			if len(raw) == 0 {
+				return nil
+			}
+			continue
+		}
+		if node != nil && node.str != nil {
+			node = node.str.value
+			if node == nil {
+				return nil
+			}
+		}
+		if node != nil && (node.object != nil || node.array != nil) {
+			node = s.child(node)
+			if node == nil {
+				return nil
+			}
+			continue
+		}
+		if node != nil {
+			raw = node.getRaw()
+			m = node.keyMatch()
+			node = nil
+		}
+		raw = s.withMatch(m).lookup(raw)
+		if len(raw) == 0 {
+			return nil
+		}
+	}
+	if node != nil {
+		return node.getRaw()
+	}
+	return raw
+}
+
+// child在已展开的object或array中查找s对应的节点，不存在时返回nil。
+func (s segment) child(j *JSON) *JSON {
+	if s.kind == segAlt {
+		return s.childAlt(j)
+	}
+	if j.array != nil {
+		if !s.indexable() {
+			return nil
+		}
+		idx, ok := normIndex(s.index, len(j.array.values))
+		if !ok {
+			return nil
+		}
+		return j.array.values[idx]
+	}
+	if s.kind != segKey {
+		return nil
+	}
+	if v := j.object.entry[s.key]; v != nil {
+		return v
+	}
+	return j.object.find(s.key, max(s.match, j.keyMatch()))
+}
+
+// lookup在原始json中查找s对应的值，不存在时返回nil。
+func (s segment) lookup(raw []byte) []byte {
+	if s.kind == segFunc {
+		return s.call(raw)
+	}
+	if s.kind == segAlt {
+		return s.lookupAlt(raw)
+	}
+	raw = strJSON(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+	if raw[0] == '[' && s.indexable() {
+		return arrayIndex(raw, s.index)
+	}
+	if raw[0] == '{' && s.kind == segKey {
+		return objectIndexMatch(raw, s.key, s.match)
+	}
+	return nil
+}
+
+// strJSON如果raw是内容为json的字符串，返回字符串的内容，否则返回raw本身。
+func strJSON(raw []byte) []byte {
+	if len(raw) == 0 || raw[0] != '"' {
+		return raw
+	}
+	s, ok := unquoteBytes(raw)
+	if !ok {
+		var str string
+		if json.Unmarshal(raw, &str) != nil {
+			return raw
+		}
+		s = unsafeBytes(str)
+	}
+	if !json.Valid(s) {
+		return raw
+	}
+	return bytes.TrimSpace(s)
+}
+
+// normIndex将下标i转换为长度为n的数组的实际下标，负数下标从尾部循环计数。
+func normIndex(i, n int) (int, bool) {
+	if i < 0 && n > 0 {
+		i = (i%n + n) % n
+	}
+	return i, 0 <= i && i < n
+}
+
+func arrayIndex(raw []byte, i int) []byte {
+	if i < 0 {
+		n := 0
+		if !scanArray(raw, func(int, []byte) bool { n++; return true }) {
+			return nil
+		}
+		var ok bool
+		if i, ok = normIndex(i, n); !ok {
+			return nil
+		}
+	}
+	var elem []byte
+	if !scanArray(raw, func(n int, val []byte) bool {
+		if n == i {
+			elem = val
+			return false
+		}
+		return true
+	}) {
+		return nil
+	}
+	return elem
+}
+
+// objectIndex返回object中key对应的值，key重复时以最后一个为准。
+func objectIndex(raw []byte, key string) []byte {
+	var val []byte
+	ok := scanObject(raw, func(rawKey, v []byte) bool {
+		if keyEqual(rawKey, key) {
+			val = v
+		}
+		return true
+	})
+	if !ok {
+		return nil
+	}
+	return val
+}
+
+// keyEqual判断带引号的原始key是否等于key。
+func keyEqual(rawKey []byte, key string) bool {
+	if t, ok := unquoteBytes(rawKey); ok {
+		return unsafeString(t) == key
+	}
+	k, ok := unquoteKey(rawKey)
+	return ok && k == key
+}
@@ -0,0 +1,102 @@
+package ejson
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	g := FromString(`{"a": [{"b": 1}, {"b": 2, "c": "{\"d\":[3]}"}], "e": "x", "e": "y"}`)
+	cases := map[string]string{
+		"a[0].b":      "1",
+		"a.1.b":       "2",
+		"a[-1].b":     "2",
+		"a[-2].b":     "1",
+		"a[-3].b":     "2",
+		"a[1].c.d":    "[3]",
+		"a[1].c.d[0]": "3",
+		"e":           `"y"`,
+		"a[2].b":      "",
+		"a.x":         "",
+		"e.x":         "",
+		"a[x]":        "",
+	}
+	for key, want := range cases {
+		if s := g.Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup %v: %q, should be %q", key, s, want)
+		}
+		if s := g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get %v: %q, should be %q", key, s, want)
+		}
+	}
+}
+
+func TestLookupInvalid(t *testing.T) {
+	// 不合法的json上，即使要找的值在出错位置之前，Lookup、GetMany也和Get一样返回不存在
+	docs := []string{`[1,2`, `[1,2,}`, `{"a":[1,2}`, `{"a":1,"b"}`, `[[1],{"a":1},x]`}
+	keys := []string{"[0]", "[-1]", "a[0]", "a", "[*]", "[0:1]", "..a", "[1].a"}
+	for _, doc := range docs {
+		for _, key := range keys {
+			want := FromString(doc).Get(key).UnsafeString()
+			if s := FromString(doc).Lookup(key).UnsafeString(); s != want {
+				t.Fatalf("lookup %v in %s: %s, get: %s", key, doc, s, want)
+			}
+			if s := FromString(doc).GetMany(key)[0].UnsafeString(); s != want {
+				t.Fatalf("get many %v in %s: %s, get: %s", key, doc, s, want)
+			}
+		}
+	}
+	if FromString(`[1,2`).Lookup("[0]").Exists() {
+		t.Fatal("lookup in truncated array should not exists")
+	}
+}
+
+func TestLookupNoSideEffect(t *testing.T) {
+	g := FromString(`{"a": {"b": [1, 2]}}`)
+	v := g.Lookup("a.b[1]")
+	if v.Int() != 2 {
+		t.Fatalf("a.b[1]: %v", v)
+	}
+	if g.object != nil || g.array != nil {
+		t.Fatal("lookup should not expand json tree")
+	}
+	v.Set(3)
+	if g.UnsafeString() != `{"a": {"b": [1, 2]}}` {
+		t.Fatalf("lookup view should not write back: %s", g.UnsafeString())
+	}
+	if g.Lookup("x.y").Exists() {
+		t.Fatal("x.y should not exists")
+	}
+}
+
+func TestLookupModified(t *testing.T) {
+	g := FromString(`{"a": {"b": [1, 2]}, "c": "{\"d\":1}"}`)
+	g.Get("a.b[0]").Set(5)
+	g.Get("c.d").Set(6)
+	g.Get("a.x")
+	if v := g.Lookup("a.b[0]").Int(); v != 5 {
+		t.Fatalf("a.b[0]: %v", v)
+	}
+	if v := g.Lookup("c.d").Int(); v != 6 {
+		t.Fatalf("c.d: %v", v)
+	}
+	if g.Lookup("a.x").Exists() {
+		t.Fatal("a.x should not exists")
+	}
+	if v := g.Lookup("a.b").UnsafeString(); v != "[5,2]" {
+		t.Fatalf("a.b: %v", v)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	data := benchPayload(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FromBytes(data).Get("key500.tags[1]")
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	data := benchPayload(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FromBytes(data).Lookup("key500.tags[1]")
+	}
+}
@@ -0,0 +1,188 @@
+package ejson
+
+// trie将多个smart key按公共前缀合并，用于一次遍历解析多个key。
+// 只有key和下标片段会插入trie，见mergeable。
+type trie struct {
+	seg      segment
+	children []*trie
+	keys     map[string]*trie // object key子节点索引
+	results  []int            // 以当前节点结尾的smart key序号
+}
+
+func (t *trie) insert(segs []segment, result int) {
+	for _, s := range segs {
+		var next *trie
+		for _, c := range t.children {
+			if c.seg.kind == s.kind && c.seg.key == s.key && c.seg.index == s.index && c.seg.numeric == s.numeric {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			next = &trie{seg: s}
+			t.children = append(t.children, next)
+			if s.kind == segKey {
+				if t.keys == nil {
+					t.keys = make(map[string]*trie)
+				}
+				t.keys[s.key] = next
+			}
+		}
+		t = next
+	}
+	t.results = append(t.results, result)
+}
+
+// mergeable判断segs是否只包含可以插入trie的key和下标片段。
+func mergeable(segs []segment) bool {
+	for _, s := range segs {
+		if (s.kind != segKey && s.kind != segIndex) || s.descend || s.match != MatchExact {
+			return false
+		}
+	}
+	return true
+}
+
+// GetMany以只读方式一次性查询多个smartKey，语法与Get相同。
+// 所有smartKey按公共前缀合并，json只遍历一次，每个object或array至多扫描一次。
+// 返回结果与smartKey一一对应，和Lookup一样，结果是独立的视图，对它的写入不会影响当前json树。
+// smartKey不合法或值不存在时，对应结果的Exists()返回false。
+func (g *JSON) GetMany(smartKeys ...string) []*JSON {
+	results := make([]*JSON, len(smartKeys))
+	root := new(trie)
+	match := g.keyMatch()
+	for i, key := range smartKeys {
+		segs, ok := parseKey(key)
+		if !ok {
+			continue
+		}
+		if g.results != nil || !mergeable(segs) || match != MatchExact {
+			// 多值片段、路径函数等无法合并到trie中，单独查询
+			results[i] = lookupJSON(g, segs)
+			continue
+		}
+		root.insert(segs, i)
+	}
+
+	raws := make([][]byte, len(smartKeys))
+	root.resolve(g, nil, raws)
+
+	for i, raw := range raws {
+		if results[i] == nil {
+			results[i] = &JSON{raw: raw}
+		}
+	}
+	return results
+}
+
+// resolve与lookup相同，优先沿已展开的json树查找，遇到未展开的节点后改为扫描原始json。
+func (t *trie) resolve(node *JSON, raw []byte, out [][]byte) {
+	if len(t.results) > 0 {
+		v := raw
+		if node != nil {
+			v = node.getRaw()
+		}
+		for _, i := range t.results {
+			out[i] = v
+		}
+	}
+	if len(t.children) == 0 {
+		return
+	}
+
+	if node != nil && node.str != nil {
+		node = node.str.value
+		if node == nil {
+			return
+		}
+	}
+	if node != nil && (node.object != nil || node.array != nil) {
+		for _, c := range t.children {
+			if child := c.seg.child(node); child != nil {
+				c.resolve(child, nil, out)
+			}
+		}
+		return
+	}
+	if node != nil {
+		raw = node.getRaw()
+	}
+
+	raw = strJSON(raw)
+	if len(raw) == 0 {
+		return
+	}
+	switch raw[0] {
+	case '[':
+		t.resolveArray(raw, out)
+	case '{':
+		t.resolveObject(raw, out)
+	}
+}
+
+func (t *trie) resolveArray(raw []byte, out [][]byte) {
+	// max是需要扫描到的最大下标，存在负数下标时需要扫描整个数组
+	max, indexed := -1, false
+	for _, c := range t.children {
+		if !c.seg.indexable() {
+			continue
+		}
+		indexed = true
+		if c.seg.index < 0 {
+			max = -1
+			break
+		}
+		if c.seg.index > max {
+			max = c.seg.index
+		}
+	}
+	if !indexed {
+		return
+	}
+
+	var elems [][]byte
+	if !scanArray(raw, func(i int, val []byte) bool {
+		elems = append(elems, val)
+		return i != max
+	}) {
+		return
+	}
+
+	for _, c := range t.children {
+		if !c.seg.indexable() {
+			continue
+		}
+		if i, ok := normIndex(c.seg.index, len(elems)); ok {
+			c.resolve(nil, elems[i], out)
+		}
+	}
+}
+
+func (t *trie) resolveObject(raw []byte, out [][]byte) {
+	if len(t.keys) == 0 {
+		return
+	}
+	vals := make(map[*trie][]byte, len(t.keys))
+	if !scanObject(raw, func(rawKey, val []byte) bool {
+		if k, ok := unquoteBytes(rawKey); ok {
+			if c := t.keys[unsafeString(k)]; c != nil {
+				vals[c] = val
+			}
+			return true
+		}
+		if key, ok := unquoteKey(rawKey); ok {
+			if c := t.keys[key]; c != nil {
+				vals[c] = val
+			}
+		}
+		return true
+	}) {
+		return
+	}
+
+	for _, c := range t.children {
+		if val := vals[c]; val != nil {
+			c.resolve(nil, val, out)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package ejson
+
+import "testing"
+
+func TestGetMany(t *testing.T) {
+	g := FromString(`{"a": [{"b": 1}, {"b": 2, "c": "{\"d\":[3]}"}], "e": "x", "e": "y", "0": "zero"}`)
+	keys := []string{"a[0].b", "a.1.b", "a[-1].b", "a[1].c.d[0]", "a[1].c.d", "e", "a[2].b", "a.x", "[0]", "0", "a.[0]", "a"}
+	results := g.GetMany(keys...)
+	if len(results) != len(keys) {
+		t.Fatalf("get many returns %v results", len(results))
+	}
+	for i, key := range keys {
+		if s, want := results[i].UnsafeString(), g.Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("get many %v: %q, should be %q", key, s, want)
+		}
+	}
+	if g.object != nil {
+		t.Fatal("get many should not expand json tree")
+	}
+
+	g.Get("a[0].b").Set(5)
+	g.Get("a[1].c.d[0]").Set(6)
+	results = g.GetMany(keys...)
+	for i, key := range keys {
+		if s, want := results[i].UnsafeString(), g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get many %v: %q, should be %q", key, s, want)
+		}
+	}
+}
+
+func BenchmarkGetMany(b *testing.B) {
+	data := benchPayload(1000)
+	keys := []string{"key1.id", "key10.name", "key100.tags[0]", "key500.score", "key999.tags[-1]"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FromBytes(data).GetMany(keys...)
+	}
+}
+
+func BenchmarkGetManyLookup(b *testing.B) {
+	data := benchPayload(1000)
+	keys := []string{"key1.id", "key10.name", "key100.tags[0]", "key500.score", "key999.tags[-1]"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g := FromBytes(data)
+		for _, key := range keys {
+			g.Lookup(key)
+		}
+	}
+}
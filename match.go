@@ -0,0 +1,131 @@
+package ejson
+
+import "strings"
+
+// KeyMatch是object key的匹配方式，用于兼容不同来源的key写法。
+// 任何匹配方式下都优先精确匹配，没有精确匹配的key时才按匹配方式查找第一个匹配的key。
+type KeyMatch uint8
+
+const (
+	MatchExact     KeyMatch = iota // 精确匹配，默认方式
+	MatchFold                      // 忽略大小写，如UserID匹配userid
+	MatchNormalize                 // 忽略大小写、'_'和'-'，如UserID、userId、user_id、user-id互相匹配
+)
+
+// equal判断key a、b在m下是否匹配。
+func (m KeyMatch) equal(a, b string) bool {
+	return m.matcher(b)(a)
+}
+
+// matcher返回判断k与key在m下是否匹配的函数，key只处理一次，用于在循环中和多个k比较。
+func (m KeyMatch) matcher(key string) func(k string) bool {
+	switch m {
+	case MatchFold:
+		return func(k string) bool { return strings.EqualFold(k, key) }
+	case MatchNormalize:
+		key = normalizeKey(key)
+		return func(k string) bool { return normalizeKey(k) == key }
+	}
+	return func(k string) bool { return k == key }
+}
+
+var keyNormalizer = strings.NewReplacer("_", "", "-", "")
+
+func normalizeKey(key string) string {
+	return strings.ToLower(keyNormalizer.Replace(key))
+}
+
+// SetKeyMatch设置当前*JSON及其所有后代的key匹配方式，影响Get、Lookup、GetMany等按smart key查找的方法。
+// ObjectIndex、JSON Pointer和jsonpath子包按各自的规范总是精确匹配key。
+// 写入不存在的key时，总是使用查找时给出的key。
+func (g *JSON) SetKeyMatch(m KeyMatch) {
+	g.match = m
+}
+
+// keyMatch返回g生效的key匹配方式，未设置时继承父节点。
+func (g *JSON) keyMatch() KeyMatch {
+	for p := g; p != nil; p = p.parent {
+		if p.match != MatchExact {
+			return p.match
+		}
+	}
+	return MatchExact
+}
+
+// find查找key，m不是精确匹配时，返回按key顺序第一个匹配的值。
+func (obj *object) find(key string, m KeyMatch) *JSON {
+	if g := obj.entry[key]; g != nil || m == MatchExact {
+		return g
+	}
+	match := m.matcher(key)
+	for _, k := range obj.keys {
+		if match(k) {
+			return obj.entry[k]
+		}
+	}
+	return nil
+}
+
+// matchIndex与ObjectIndex相同，但按m和g上SetKeyMatch设置的匹配方式中较宽松的一个匹配key。
+// smart key按key查找都经过matchIndex，只有这里做非精确匹配。
+func (g *JSON) matchIndex(key string, m KeyMatch) *JSON {
+	if m = max(m, g.keyMatch()); m != MatchExact && g.IsObject() && g.asObject() {
+		if v := g.object.find(key, m); v != nil {
+			return v
+		}
+	}
+	return g.ObjectIndex(key)
+}
+
+// withMatch返回至少按m匹配key的片段。
+func (s segment) withMatch(m KeyMatch) segment {
+	if m <= s.match {
+		return s
+	}
+	s.match = m
+	if s.alts != nil {
+		s.alts = withMatch(s.alts, m)
+	}
+	return s
+}
+
+func withMatch(segs []segment, m KeyMatch) []segment {
+	if m == MatchExact {
+		return segs
+	}
+	matched := make([]segment, len(segs))
+	for i, s := range segs {
+		matched[i] = s.withMatch(m)
+	}
+	return matched
+}
+
+// objectIndexMatch与objectIndex相同，但m不是精确匹配时，没有精确匹配的key则返回第一个匹配的值。
+func objectIndexMatch(raw []byte, key string, m KeyMatch) []byte {
+	if m == MatchExact {
+		return objectIndex(raw, key)
+	}
+	var exact, fuzzy []byte
+	var fuzzyKey string
+	match := m.matcher(key)
+	ok := scanObject(raw, func(rawKey, v []byte) bool {
+		k, ok := unquoteKey(rawKey)
+		if !ok {
+			return false
+		}
+		if k == key {
+			exact = v
+		} else if fuzzy == nil && match(k) || fuzzy != nil && k == fuzzyKey {
+			// 重复key以最后一个为准
+			fuzzy, fuzzyKey = v, k
+		}
+		return true
+	})
+	if !ok {
+		return nil
+	}
+	if exact != nil {
+		return exact
+	}
+	return fuzzy
+}
@@ -0,0 +1,99 @@
+package ejson
+
+import "testing"
+
+func TestKeyMatch(t *testing.T) {
+	cases := []struct {
+		m    KeyMatch
+		a, b string
+		want bool
+	}{
+		{MatchExact, "userId", "userId", true},
+		{MatchExact, "userId", "userid", false},
+		{MatchFold, "UserID", "userid", true},
+		{MatchFold, "user_id", "userId", false},
+		{MatchNormalize, "UserID", "user_id", true},
+		{MatchNormalize, "userId", "user-id", true},
+		{MatchNormalize, "userId", "user_name", false},
+	}
+	for _, c := range cases {
+		if got := c.m.equal(c.a, c.b); got != c.want {
+			t.Fatalf("match %v %q %q: %v", c.m, c.a, c.b, got)
+		}
+	}
+}
+
+func TestSetKeyMatch(t *testing.T) {
+	const doc = `{"data":{"UserID":1,"user_name":"a","userId":2,"Items":[{"Item_ID":3}]}}`
+
+	g := FromString(doc)
+	g.SetKeyMatch(MatchNormalize)
+	cases := map[string]string{
+		"data.userId":          `2`, // 优先精确匹配
+		"data.userid":          `1`,
+		"DATA.userName":        `"a"`,
+		"data.items[0].itemId": `3`,
+		"data.items[*].itemId": `[3]`,
+		"data.x":               ``,
+	}
+	for key, want := range cases {
+		if s := g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get %v: %s, should be %s", key, s, want)
+		}
+		if s := g.Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup %v: %s, should be %s", key, s, want)
+		}
+		if s := g.GetMany(key)[0].UnsafeString(); s != want {
+			t.Fatalf("get many %v: %s, should be %s", key, s, want)
+		}
+	}
+
+	// 未展开的json同样生效
+	g = FromString(doc)
+	g.SetKeyMatch(MatchFold)
+	if s := g.Lookup("DATA.userid").UnsafeString(); s != `1` {
+		t.Fatalf("lookup fold: %s", s)
+	}
+	if g.Lookup("data.user_id").Exists() {
+		t.Fatal("fold should not normalize user_id")
+	}
+	// ObjectIndex、JSON Pointer总是精确匹配
+	if g.ObjectIndex("Data").Exists() || g.ObjectIndex("data").ObjectIndex("userid").Exists() {
+		t.Fatal("object index should match exactly")
+	}
+	if g.GetPointer("/DATA/userid").Exists() || g.GetPointer("/data/UserID").Int() != 1 {
+		t.Fatal("JSON pointer should match exactly")
+	}
+
+	// 写入匹配到的key，不存在时写入给出的key
+	g.Get("data.userid").Set(10)
+	g.Get("data.newKey").Set(true)
+	if s := g.String(); s != `{"data":{"UserID":10,"user_name":"a","userId":2,"Items":[{"Item_ID":3}],"newKey":true}}` {
+		t.Fatalf("set: %s", s)
+	}
+}
+
+func TestPathWithKeyMatch(t *testing.T) {
+	g := FromString(`{"data":{"user_id":1,"Name":"a"},"items":[{"ID":1}]}`)
+	p := MustCompile("data.userId").WithKeyMatch(MatchNormalize)
+	if s := p.Get(g).UnsafeString(); s != `1` {
+		t.Fatalf("get: %s", s)
+	}
+	if s := p.Lookup(g).UnsafeString(); s != `1` {
+		t.Fatalf("lookup: %s", s)
+	}
+	if !p.Exists(g) {
+		t.Fatal("should exists")
+	}
+	if MustCompile("data.userId").Exists(g) {
+		t.Fatal("exact path should not match")
+	}
+	if s := MustCompile("(items|list)[*].id").WithKeyMatch(MatchFold).Lookup(g).UnsafeString(); s != `[1]` {
+		t.Fatalf("lookup alternation: %s", s)
+	}
+
+	p.Set(g, 2)
+	if s := g.String(); s != `{"data":{"user_id":2,"Name":"a"},"items":[{"ID":1}]}` {
+		t.Fatalf("set: %s", s)
+	}
+}
@@ -0,0 +1,126 @@
+package ejson
+
+import "bytes"
+
+// Conflict是Merge3中ours、theirs对同一位置做了不同修改的冲突。
+// 值不存在表示该位置在对应版本中不存在，如theirs删除了ours修改的值。
+type Conflict struct {
+	Path   string // 冲突位置的JSON Pointer
+	Base   *JSON
+	Ours   *JSON
+	Theirs *JSON
+}
+
+// Resolver决定冲突位置合并后的值，返回不存在的值或nil表示删除该位置。
+type Resolver func(c Conflict) *JSON
+
+var (
+	// ResolveOurs使用ours的值，是Merge3的默认方式。
+	ResolveOurs Resolver = func(c Conflict) *JSON { return c.Ours }
+	// ResolveTheirs使用theirs的值。
+	ResolveTheirs Resolver = func(c Conflict) *JSON { return c.Theirs }
+)
+
+// Merge3以base为共同祖先三路合并ours、theirs，返回合并后的新json和所有冲突，base、ours、theirs本身不会被修改。
+//
+// 只有一方修改的值使用修改后的值，两方修改相同时直接使用；两方都是object时逐个key合并，
+// 三方都是长度相同的array时逐个下标合并，其它情况两方修改不同即为冲突。
+// 冲突按resolve决定合并后的值，默认ResolveOurs；resolve可以是回调，对每个冲突分别选择ours、theirs或其它值。
+func Merge3(base, ours, theirs *JSON, resolve ...Resolver) (*JSON, []Conflict) {
+	m := &merger{resolve: ResolveOurs}
+	if len(resolve) > 0 && resolve[0] != nil {
+		m.resolve = resolve[0]
+	}
+	raw := m.merge("", view(base), view(ours), view(theirs))
+	return &JSON{raw: raw}, m.conflicts
+}
+
+type merger struct {
+	resolve   Resolver
+	conflicts []Conflict
+}
+
+// merge返回path位置合并后的原始json，nil表示合并后不存在。
+func (m *merger) merge(path string, base, ours, theirs *JSON) []byte {
+	switch {
+	case jsonEqual(ours, theirs), jsonEqual(base, theirs):
+		return ours.getRaw()
+	case jsonEqual(base, ours):
+		return theirs.getRaw()
+	}
+
+	if ours.IsObject() && theirs.IsObject() && (base.IsObject() || !base.Exists()) {
+		return m.mergeObject(path, base, ours, theirs)
+	}
+	if base.IsArray() && ours.IsArray() && theirs.IsArray() &&
+		base.Len() == ours.Len() && base.Len() == theirs.Len() {
+		return m.mergeArray(path, base, ours, theirs)
+	}
+
+	c := Conflict{Path: path, Base: base, Ours: ours, Theirs: theirs}
+	m.conflicts = append(m.conflicts, c)
+	if v := m.resolve(c); v != nil {
+		return v.getRaw()
+	}
+	return nil
+}
+
+func (m *merger) mergeObject(path string, base, ours, theirs *JSON) []byte {
+	base.asObject()
+	ours.asObject()
+	theirs.asObject()
+	child := func(g *JSON, key string) *JSON {
+		if g.object != nil && g.object.entry[key] != nil {
+			return g.object.entry[key]
+		}
+		return new(JSON)
+	}
+
+	// ours的key在前，ours中没有的key按theirs中的顺序追加在后，两方都删除的key不需要合并
+	keys := ours.Keys()
+	for _, key := range theirs.object.keys {
+		if ours.object.entry[key] == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, key := range keys {
+		raw := m.merge(path+"/"+escapePointerToken(key), child(base, key), child(ours, key), child(theirs, key))
+		if len(raw) == 0 {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		k, _ := marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(raw)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+func (m *merger) mergeArray(path string, base, ours, theirs *JSON) []byte {
+	base.asArray()
+	ours.asArray()
+	theirs.asArray()
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, v := range base.array.values {
+		raw := m.merge(elemPath(path, i), v, ours.array.values[i], theirs.array.values[i])
+		if len(raw) == 0 {
+			// 冲突解决为删除时，保留null占位，不影响其它元素的下标
+			raw = []byte("null")
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(raw)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
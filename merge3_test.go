@@ -0,0 +1,75 @@
+package ejson
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	cases := []struct {
+		base, ours, theirs string
+		want               string
+		conflicts          []string
+	}{
+		{`{"a":1,"b":1}`, `{"a":2,"b":1}`, `{"a":1,"b":2}`, `{"a":2,"b":2}`, nil},
+		{`{"a":1}`, `{"a":1,"b":1}`, `{"a":1,"c":1}`, `{"a":1,"b":1,"c":1}`, nil},
+		{`{"a":1,"b":1}`, `{"b":1}`, `{"a":1}`, `{}`, nil},
+		{`{"a":1}`, `{"a":2}`, `{"a":2}`, `{"a":2}`, nil},
+		{`{"a":{"x":1,"y":1}}`, `{"a":{"x":2,"y":1}}`, `{"a":{"x":1,"y":2}}`, `{"a":{"x":2,"y":2}}`, nil},
+		{`{"a":[1,2,3]}`, `{"a":[0,2,3]}`, `{"a":[1,2,4]}`, `{"a":[0,2,4]}`, nil},
+		{`{"a":1}`, `{"a":2}`, `{"a":3}`, `{"a":2}`, []string{"/a"}},
+		{`{"a":1}`, `{"a":2}`, `{}`, `{"a":2}`, []string{"/a"}},
+		{`{"a":[1]}`, `{"a":[1,2]}`, `{"a":[0]}`, `{"a":[1,2]}`, []string{"/a"}},
+		{`{}`, `{"a":{"x":1}}`, `{"a":{"y":1}}`, `{"a":{"x":1,"y":1}}`, nil},
+		{`{}`, `{"a":{"x":1}}`, `{"a":{"x":2}}`, `{"a":{"x":1}}`, []string{"/a/x"}},
+		{`{"a":{"b":1}}`, `{"a":{"b":2},"c":1}`, `{"a":3,"c":2}`, `{"a":{"b":2},"c":1}`, []string{"/a", "/c"}},
+		{`1`, `2`, `3`, `2`, []string{""}},
+		{``, `{"a":1}`, `{"b":1}`, `{"a":1,"b":1}`, nil},
+	}
+	for _, c := range cases {
+		base, ours, theirs := FromString(c.base), FromString(c.ours), FromString(c.theirs)
+		got, conflicts := Merge3(base, ours, theirs)
+		if s := got.UnsafeString(); s != c.want {
+			t.Fatalf("merge %s %s %s: %s, should be %s", c.base, c.ours, c.theirs, s, c.want)
+		}
+		if len(conflicts) != len(c.conflicts) {
+			t.Fatalf("merge %s %s %s: conflicts %v, should be %v", c.base, c.ours, c.theirs, conflicts, c.conflicts)
+		}
+		for i, conflict := range conflicts {
+			if conflict.Path != c.conflicts[i] {
+				t.Fatalf("merge %s %s %s: conflict %v at %q, should be %q", c.base, c.ours, c.theirs, i, conflict.Path, c.conflicts[i])
+			}
+		}
+		if base.UnsafeString() != c.base || ours.UnsafeString() != c.ours || theirs.UnsafeString() != c.theirs {
+			t.Fatalf("merge %s %s %s: inputs modified", c.base, c.ours, c.theirs)
+		}
+	}
+}
+
+func TestMerge3Resolve(t *testing.T) {
+	base := FromString(`{"a":1,"b":1,"c":1,"d":[1]}`)
+	ours := FromString(`{"a":2,"b":2,"c":2,"d":[1]}`)
+	theirs := FromString(`{"a":3,"b":3,"d":[1,2]}`)
+
+	got, conflicts := Merge3(base, ours, theirs, ResolveTheirs)
+	if s := got.String(); s != `{"a":3,"b":3,"d":[1,2]}` {
+		t.Fatalf("resolve theirs: %s", s)
+	}
+	if len(conflicts) != 3 {
+		t.Fatalf("conflicts: %v", conflicts)
+	}
+	c := conflicts[2]
+	if c.Path != "/c" || c.Base.Int() != 1 || c.Ours.Int() != 2 || c.Theirs.Exists() {
+		t.Fatalf("conflict: %+v", c)
+	}
+
+	got, _ = Merge3(base, ours, theirs, func(c Conflict) *JSON {
+		switch c.Path {
+		case "/a":
+			return c.Ours
+		case "/b":
+			return nil
+		}
+		return newValue("custom")
+	})
+	if s := got.String(); s != `{"a":2,"c":"custom","d":[1,2]}` {
+		t.Fatalf("resolve callback: %s", s)
+	}
+}
@@ -0,0 +1,71 @@
+package ejson
+
+import "errors"
+
+// MergePatch按RFC 7396将patch合并到当前*JSON：patch是object时逐个key递归合并，
+// 值为null的key从当前object中删除；patch不是object时直接替换当前值。
+// 当前值不存在或不是object时，先替换为{}再合并。key总是精确匹配，不受SetKeyMatch影响。
+// 对Get返回的多值结果，patch合并到每个匹配值。patch不存在或不是合法json时返回错误。
+func (g *JSON) MergePatch(patch *JSON) error {
+	if patch == nil || !Valid(patch.getRaw()) {
+		return errors.New("ejson: invalid merge patch")
+	}
+	if g.results != nil {
+		for _, js := range g.results.nodes {
+			mergePatch(js, patch)
+		}
+		return nil
+	}
+	mergePatch(g, patch)
+	return nil
+}
+
+func mergePatch(g, patch *JSON) {
+	if !patch.IsObject() || !patch.asObject() {
+		g.reset(patch.getRaw())
+		return
+	}
+	if !g.IsObject() || !g.asObject() {
+		g.reset([]byte("{}"))
+		g.asObject()
+	}
+	for _, key := range patch.object.keys {
+		v := patch.object.entry[key]
+		if v.IsNull() {
+			if old := g.object.entry[key]; old != nil {
+				old.Remove()
+			}
+			continue
+		}
+		mergePatch(g.object.Index(key), v)
+	}
+}
+
+// CreateMergePatch生成从from到to的RFC 7396 merge patch，即from.MergePatch(patch)之后与to相同。
+// from、to都是object时只包含有变化的key，from中被删除的key值为null；否则patch就是to。
+// merge patch无法表示值为null的key，to中值为null的key在合并后会被删除。
+func CreateMergePatch(from, to *JSON) *JSON {
+	if !from.IsObject() || !to.IsObject() || !from.asObject() || !to.asObject() {
+		return &JSON{raw: to.getRaw()}
+	}
+
+	patch := &JSON{raw: []byte("{}")}
+	for _, key := range from.object.keys {
+		if to.object.entry[key] == nil {
+			patch.ObjectIndex(key).reset([]byte("null"))
+		}
+	}
+	for _, key := range to.object.keys {
+		v := to.object.entry[key]
+		old := from.object.entry[key]
+		if old != nil && jsonEqual(old, v) {
+			continue
+		}
+		if old == nil {
+			patch.ObjectIndex(key).reset(v.getRaw())
+			continue
+		}
+		patch.ObjectIndex(key).reset(CreateMergePatch(old, v).getRaw())
+	}
+	return patch
+}
@@ -0,0 +1,84 @@
+package ejson
+
+import "testing"
+
+func TestMergePatch(t *testing.T) {
+	// RFC 7396 Appendix A
+	cases := []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{``, `{"a":{"b":1}}`, `{"a":{"b":1}}`},
+	}
+	for _, c := range cases {
+		g := FromString(c.target)
+		if err := g.MergePatch(FromString(c.patch)); err != nil {
+			t.Fatalf("merge %s into %s: %v", c.patch, c.target, err)
+		}
+		if s := g.UnsafeString(); s != c.want {
+			t.Fatalf("merge %s into %s: %s, should be %s", c.patch, c.target, s, c.want)
+		}
+	}
+
+	g := FromString(`{"a":{"b":1}}`)
+	if err := g.Get("a").MergePatch(FromString(`{"c":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	if s := g.String(); s != `{"a":{"b":1,"c":2}}` {
+		t.Fatalf("merge into child: %s", s)
+	}
+
+	g = FromString(`{"items":[{"a":1},{"a":2}]}`)
+	g.Get("items[*]").MergePatch(FromString(`{"a":null,"b":0}`))
+	if s := g.String(); s != `{"items":[{"b":0},{"b":0}]}` {
+		t.Fatalf("merge into results: %s", s)
+	}
+
+	if err := g.MergePatch(FromString(`{`)); err == nil {
+		t.Fatal("invalid patch should return error")
+	}
+	if err := g.MergePatch(new(JSON)); err == nil {
+		t.Fatal("missing patch should return error")
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	cases := []struct {
+		from, to, want string
+	}{
+		{`{"a":1,"b":2}`, `{"a":1,"b":3}`, `{"b":3}`},
+		{`{"a":1,"b":2}`, `{"b":2,"a":1}`, `{}`},
+		{`{"a":1,"b":2}`, `{"a":1}`, `{"b":null}`},
+		{`{"a":1}`, `{"a":1,"c":[1]}`, `{"c":[1]}`},
+		{`{"a":{"x":1,"y":2}}`, `{"a":{"x":1,"y":3,"z":0}}`, `{"a":{"y":3,"z":0}}`},
+		{`{"a":{"x":1}}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"a":[1,2]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"a":1.0}`, `{"a":1}`, `{}`},
+		{`[1]`, `[2]`, `[2]`},
+	}
+	for _, c := range cases {
+		from, to := FromString(c.from), FromString(c.to)
+		patch := CreateMergePatch(from, to)
+		if s := patch.UnsafeString(); s != c.want {
+			t.Fatalf("create merge patch %s -> %s: %s, should be %s", c.from, c.to, s, c.want)
+		}
+		from.MergePatch(patch)
+		if !jsonEqual(from, to) {
+			t.Fatalf("apply merge patch %s to %s: %s", patch, c.from, from)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package ejson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// position返回g在父节点中的位置：object中的key，或array中的下标(key为空)。
+// g是内容为json的字符串的值时elem为true；g不在json树中时ok为false。
+func (g *JSON) position() (key string, index int, elem, ok bool) {
+	p := g.parent
+	if p == nil {
+		return "", -1, false, false
+	}
+	if p.str != nil && p.str.value == g {
+		return "", -1, true, true
+	}
+	if p.object != nil {
+		for _, k := range p.object.keys {
+			if p.object.entry[k] == g {
+				return k, -1, false, true
+			}
+		}
+	}
+	if p.array != nil {
+		for i, v := range p.array.values {
+			if v == g {
+				return "", i, false, true
+			}
+		}
+	}
+	return "", -1, false, false
+}
+
+// Parent返回当前*JSON所在的object或array。当前*JSON是内容为json的字符串的值时，返回该字符串。
+// 当前*JSON不在json树中(如根节点、已Remove或尚未写入的值)时返回nil。
+func (g *JSON) Parent() *JSON {
+	if _, _, _, ok := g.position(); !ok {
+		return nil
+	}
+	return g.parent
+}
+
+// Root返回当前*JSON所在json树的根节点，当前*JSON不在json树中时返回它自身。
+func (g *JSON) Root() *JSON {
+	for p := g.Parent(); p != nil; p = g.Parent() {
+		g = p
+	}
+	return g
+}
+
+// Key返回当前*JSON在object中的key，不是object的值时ok为false。
+func (g *JSON) Key() (key string, ok bool) {
+	key, index, elem, ok := g.position()
+	return key, ok && !elem && index < 0
+}
+
+// Index返回当前*JSON在array中的下标，不是array的值时ok为false。
+func (g *JSON) Index() (index int, ok bool) {
+	_, index, _, ok = g.position()
+	return index, ok && index >= 0
+}
+
+// Path返回从Root到当前*JSON的smart key，如`data.items[0]."a.b"`，根节点返回""。
+// 内容为json的字符串在smart key中自动展开，不占用路径。
+func (g *JSON) Path() string {
+	var parts []string
+	for {
+		key, index, elem, ok := g.position()
+		if !ok {
+			break
+		}
+		if !elem {
+			if index >= 0 {
+				parts = append(parts, "["+strconv.Itoa(index)+"]")
+			} else {
+				parts = append(parts, QuoteKey(key))
+			}
+		}
+		g = g.parent
+	}
+
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		if b.Len() > 0 && parts[i][0] != '[' {
+			b.WriteByte('.')
+		}
+		b.WriteString(parts[i])
+	}
+	return b.String()
+}
+
+// Pointer返回从Root到当前*JSON的RFC 6901 JSON Pointer，如"/data/items/0/a.b"，根节点返回""。
+// 内容为json的字符串中的值，其Pointer与Path一样展开字符串，这样的Pointer不能用于GetPointer。
+func (g *JSON) Pointer() string {
+	var tokens []string
+	for {
+		key, index, elem, ok := g.position()
+		if !ok {
+			break
+		}
+		if !elem {
+			if index >= 0 {
+				tokens = append(tokens, strconv.Itoa(index))
+			} else {
+				tokens = append(tokens, escapePointerToken(key))
+			}
+		}
+		g = g.parent
+	}
+
+	var b strings.Builder
+	for i := len(tokens) - 1; i >= 0; i-- {
+		b.WriteByte('/')
+		b.WriteString(tokens[i])
+	}
+	return b.String()
+}
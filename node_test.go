@@ -0,0 +1,84 @@
+package ejson
+
+import "testing"
+
+func TestNodePath(t *testing.T) {
+	g := FromString(`{"data":{"items":[{"id":1},{"a.b":{"c/d":2}}]},"s":"{\"x\":[3]}"}`)
+	cases := []struct {
+		key, path, ptr string
+	}{
+		{"data", "data", "/data"},
+		{"data.items[0].id", "data.items[0].id", "/data/items/0/id"},
+		{`data.items[1]."a.b".c/d`, `data.items[1]."a.b".c/d`, "/data/items/1/a.b/c~1d"},
+		{"s.x[0]", "s.x[0]", "/s/x/0"},
+	}
+	for _, c := range cases {
+		js := g.Get(c.key)
+		if p := js.Path(); p != c.path {
+			t.Fatalf("%v path: %q, should be %q", c.key, p, c.path)
+		}
+		if p := js.Pointer(); p != c.ptr {
+			t.Fatalf("%v pointer: %q, should be %q", c.key, p, c.ptr)
+		}
+		if js.Root() != g {
+			t.Fatalf("%v root is not g", c.key)
+		}
+		if g.Get(js.Path()) != js {
+			t.Fatalf("%v path does not point to itself", c.key)
+		}
+	}
+
+	arr := FromString(`[[1,2]]`)
+	if p := arr.Get("[0][1]").Path(); p != "[0][1]" {
+		t.Fatalf("array path: %q", p)
+	}
+	if g.Path() != "" || g.Pointer() != "" || g.Root() != g || g.Parent() != nil {
+		t.Fatal("root introspection")
+	}
+}
+
+func TestNodeParent(t *testing.T) {
+	g := FromString(`{"a":[{"b":1}]}`)
+	a := g.Get("a")
+	elem := g.Get("a[0]")
+	b := g.Get("a[0].b")
+
+	if a.Parent() != g || elem.Parent() != a || b.Parent() != elem {
+		t.Fatal("parent")
+	}
+	if key, ok := b.Key(); !ok || key != "b" {
+		t.Fatalf("key: %q, %v", key, ok)
+	}
+	if _, ok := b.Index(); ok {
+		t.Fatal("object value should have no index")
+	}
+	if i, ok := elem.Index(); !ok || i != 0 {
+		t.Fatalf("index: %v, %v", i, ok)
+	}
+	if _, ok := elem.Key(); ok {
+		t.Fatal("array value should have no key")
+	}
+
+	// 尚未写入和已移除的值不在json树中
+	missing := g.Get("a[0].c")
+	if missing.Parent() != nil || missing.Path() != "" {
+		t.Fatal("missing value should not be in tree")
+	}
+	missing.Set(2)
+	if missing.Parent() != elem || missing.Path() != "a[0].c" {
+		t.Fatalf("set value path: %q", missing.Path())
+	}
+	b.Remove()
+	if b.Parent() != nil || b.Root() != b {
+		t.Fatal("removed value should not be in tree")
+	}
+	if _, ok := b.Key(); ok {
+		t.Fatal("removed value should have no key")
+	}
+
+	s := FromString(`{"s":"{\"x\":1}"}`)
+	x := s.Get("s.x")
+	if p := x.Parent(); p == nil || p.Parent() != s.Get("s") {
+		t.Fatal("string json parent")
+	}
+}
@@ -3,6 +3,9 @@ package ejson
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
 )
 
 type object struct {
@@ -26,44 +29,23 @@ func (g *JSON) asObject() bool {
 		return true
 	}
 
-	dec := json.NewDecoder(bytes.NewReader(g.getRaw()))
-	dec.UseNumber()
-
-	leftDelim, err := dec.Token()
-	if err != nil {
-		return false
-	}
-	if d, ok := leftDelim.(json.Delim); !ok || d != '{' {
-		return false
-	}
-
 	obj := new(object)
-	for dec.More() {
-		token, err := dec.Token()
-		if err != nil {
-			return false
-		}
-		key, ok := token.(string)
+	ok := scanObject(g.getRaw(), func(rawKey, val []byte) bool {
+		key, ok := unquoteKey(rawKey)
 		if !ok {
+			obj = nil
 			return false
 		}
-		var val json.RawMessage
-		err = dec.Decode(&val)
-		if err != nil {
-			return false
-		}
-		obj.keys = append(obj.keys, key)
 		if obj.entry == nil {
 			obj.entry = make(map[string]*JSON)
 		}
+		if _, ok := obj.entry[key]; !ok {
+			obj.keys = append(obj.keys, key)
+		}
 		obj.entry[key] = &JSON{raw: val, parent: g}
-	}
-
-	rightDelim, err := dec.Token()
-	if err != nil {
-		return false
-	}
-	if d, ok := rightDelim.(json.Delim); !ok || d != '}' {
+		return true
+	})
+	if !ok || obj == nil {
 		return false
 	}
 
@@ -140,7 +122,8 @@ func (obj *object) Index(key string) *JSON {
 
 	parent := obj.parent
 	g.update = obj.update.append(func() {
-		if obj.parent != parent {
+		if obj.parent != parent || g.parent != parent {
+			// object已被替换，或g已被Remove、Detach
 			return
 		}
 
@@ -188,3 +171,149 @@ func (g *JSON) Any(keys ...string) *JSON {
 	}
 	return new(JSON)
 }
+
+func (obj *object) indexOf(key string) int {
+	for i, k := range obj.keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// editObject返回g的object，g不是object时返回错误。
+func (g *JSON) editObject() (*object, error) {
+	if !g.IsObject() || !g.asObject() {
+		return nil, fmt.Errorf("ejson: %v is not an object", g.Kind())
+	}
+	return g.object, nil
+}
+
+// InsertBefore在key之前插入newKey，值为v。key不存在时返回错误；
+// newKey已存在时，移动到key之前并替换为v；newKey等于key时只替换值。
+func (g *JSON) InsertBefore(key, newKey string, v any) error {
+	return g.insertKey(key, newKey, v, 0)
+}
+
+// InsertAfter在key之后插入newKey，其它与InsertBefore相同。
+func (g *JSON) InsertAfter(key, newKey string, v any) error {
+	return g.insertKey(key, newKey, v, 1)
+}
+
+func (g *JSON) insertKey(key, newKey string, v any, offset int) error {
+	raw, err := marshal(v)
+	if err != nil {
+		return err
+	}
+	obj, err := g.editObject()
+	if err != nil {
+		return err
+	}
+	if obj.indexOf(key) < 0 {
+		return fmt.Errorf("ejson: key %q not found", key)
+	}
+	if newKey == key {
+		obj.entry[key].reset(raw)
+		return nil
+	}
+
+	if old := obj.entry[newKey]; old != nil {
+		old.parent = nil
+		obj.keys = slices.Delete(obj.keys, obj.indexOf(newKey), obj.indexOf(newKey)+1)
+	}
+	obj.keys = slices.Insert(obj.keys, obj.indexOf(key)+offset, newKey)
+	obj.entry[newKey] = &JSON{raw: raw, parent: g}
+	g.modified()
+	return nil
+}
+
+// RenameKey将key oldKey改名为newKey，位置和值不变。oldKey不存在或newKey已存在时返回错误。
+func (g *JSON) RenameKey(oldKey, newKey string) error {
+	obj, err := g.editObject()
+	if err != nil {
+		return err
+	}
+	i := obj.indexOf(oldKey)
+	if i < 0 {
+		return fmt.Errorf("ejson: key %q not found", oldKey)
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	if obj.entry[newKey] != nil {
+		return fmt.Errorf("ejson: key %q already exists", newKey)
+	}
+	obj.keys[i] = newKey
+	obj.entry[newKey] = obj.entry[oldKey]
+	delete(obj.entry, oldKey)
+	g.modified()
+	return nil
+}
+
+// MoveKey将key移动到第i个位置，负数i从尾部计数，如-1表示移动到最后。
+// i超出范围时移动到开头或末尾，key不存在时返回错误。
+func (g *JSON) MoveKey(key string, i int) error {
+	obj, err := g.editObject()
+	if err != nil {
+		return err
+	}
+	j := obj.indexOf(key)
+	if j < 0 {
+		return fmt.Errorf("ejson: key %q not found", key)
+	}
+	n := len(obj.keys)
+	if i < 0 {
+		i += n
+	}
+	i = min(max(i, 0), n-1)
+	if i == j {
+		return nil
+	}
+	obj.keys = slices.Insert(slices.Delete(obj.keys, j, j+1), i, key)
+	g.modified()
+	return nil
+}
+
+// SortKeys按less对object的key稳定排序，less为nil时按字典序排序。不是object时不做任何操作。
+// 对Get返回的多值结果，对每个匹配值排序。
+func (g *JSON) SortKeys(less func(a, b string) bool) {
+	g.sortKeys(less, false)
+}
+
+// SortKeysRecursive与SortKeys相同，但同时对所有后代object排序，包括array中的object。
+func (g *JSON) SortKeysRecursive(less func(a, b string) bool) {
+	g.sortKeys(less, true)
+}
+
+func (g *JSON) sortKeys(less func(a, b string) bool, recursive bool) {
+	if less == nil {
+		less = func(a, b string) bool { return a < b }
+	}
+	if g.results != nil {
+		for _, js := range g.results.nodes {
+			js.sortKeys(less, recursive)
+		}
+		return
+	}
+
+	if g.IsObject() && g.asObject() {
+		keys := g.object.keys
+		if !sort.SliceIsSorted(keys, func(i, j int) bool { return less(keys[i], keys[j]) }) {
+			sort.SliceStable(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+			g.modified()
+		}
+		if recursive {
+			for _, key := range keys {
+				g.object.entry[key].sortKeys(less, true)
+			}
+		}
+		return
+	}
+	if recursive && g.IsArray() && g.asArray() {
+		for _, v := range g.array.values {
+			if v != nil {
+				v.sortKeys(less, true)
+			}
+		}
+	}
+}
@@ -21,6 +21,25 @@ func TestKeys(t *testing.T) {
 	}
 }
 
+func TestDuplicateKeys(t *testing.T) {
+	// 重复的key只保留最后一个值，位置为第一次出现的位置
+	g := FromString(`{"a":1,"b":2,"a":3}`)
+	if n := g.Len(); n != 2 {
+		t.Fatalf("len: %v", n)
+	}
+	keys := g.Keys()
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("keys: %v", keys)
+	}
+	if v := g.ObjectIndex("a").Int(); v != 3 {
+		t.Fatalf("a: %v", v)
+	}
+	g.ObjectIndex("b").Set(4)
+	if s := g.String(); s != `{"a":3,"b":4}` {
+		t.Fatalf("marshal: %s", s)
+	}
+}
+
 func TestAny(t *testing.T) {
 	g := FromString(`{"err_code":1, "errcode":"2"}`)
 	if c := g.Any("err_code", "errcode").Int(); c != 1 {
@@ -30,3 +49,75 @@ func TestAny(t *testing.T) {
 		t.Fatalf("errcode: %v", c)
 	}
 }
+
+func TestKeyOrder(t *testing.T) {
+	g := FromString(`{"a":1,"b":2,"c":3}`)
+	b := g.Get("b")
+	if err := g.InsertBefore("b", "x", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.InsertAfter("c", "y", []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	if s := g.String(); s != `{"a":1,"x":0,"b":2,"c":3,"y":[1]}` {
+		t.Fatalf("insert: %s", s)
+	}
+	// 已存在的key移动到新位置
+	if err := g.InsertBefore("a", "c", 4); err != nil {
+		t.Fatal(err)
+	}
+	if s := g.String(); s != `{"c":4,"a":1,"x":0,"b":2,"y":[1]}` {
+		t.Fatalf("insert existing: %s", s)
+	}
+
+	if err := g.RenameKey("b", "z"); err != nil {
+		t.Fatal(err)
+	}
+	b.Set(5)
+	if s := g.String(); s != `{"c":4,"a":1,"x":0,"z":5,"y":[1]}` {
+		t.Fatalf("rename: %s", s)
+	}
+	if k, _ := b.Key(); k != "z" {
+		t.Fatalf("renamed key: %v", k)
+	}
+
+	if err := g.MoveKey("c", -1); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.MoveKey("y", 0); err != nil {
+		t.Fatal(err)
+	}
+	if s := g.String(); s != `{"y":[1],"a":1,"x":0,"z":5,"c":4}` {
+		t.Fatalf("move: %s", s)
+	}
+
+	for _, err := range []error{
+		g.InsertAfter("none", "k", 1),
+		g.RenameKey("none", "k"),
+		g.RenameKey("a", "x"),
+		g.MoveKey("none", 0),
+		g.Get("a").RenameKey("a", "b"),
+	} {
+		if err == nil {
+			t.Fatal("should return error")
+		}
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	g := FromString(`{"b":{"d":1,"c":2},"a":[{"y":1,"x":2}]}`)
+	g.SortKeys(nil)
+	if s := g.String(); s != `{"a":[{"y":1,"x":2}],"b":{"d":1,"c":2}}` {
+		t.Fatalf("sort: %s", s)
+	}
+	g.SortKeysRecursive(func(a, b string) bool { return a > b })
+	if s := g.String(); s != `{"b":{"d":1,"c":2},"a":[{"y":1,"x":2}]}` {
+		t.Fatalf("sort recursive: %s", s)
+	}
+
+	g = FromString(`[{"b":1,"a":2},{"d":1,"c":2}]`)
+	g.Get("[*]").SortKeys(nil)
+	if s := g.String(); s != `[{"a":2,"b":1},{"c":2,"d":1}]` {
+		t.Fatalf("sort results: %s", s)
+	}
+}
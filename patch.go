@@ -0,0 +1,231 @@
+package ejson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PatchError表示JSON Patch中的某个操作失败。
+type PatchError struct {
+	Index int    // 失败的操作在patch中的下标
+	Op    string // 操作类型，如"add"
+	Path  string // 操作的path
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("ejson: JSON patch operation %d (%s %q): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// patchOp是解析后的一个JSON Patch操作。
+type patchOp struct {
+	op    string
+	path  string
+	from  string
+	value *JSON
+}
+
+// ApplyPatch按RFC 6902将patch中的操作(add、remove、replace、move、copy、test)依次应用到当前*JSON，
+// path、from是RFC 6901 JSON Pointer，见GetPointer。
+//
+// 所有操作都成功后才写入当前*JSON，任一操作失败时当前*JSON保持不变，返回*PatchError，
+// 其Index是失败操作的下标。与Set一样，写入后之前通过Get等取得的子节点将不再属于json树。
+// 对Get返回的多值结果，patch应用到每个匹配值，任一匹配值失败时所有匹配值都保持不变。
+func (g *JSON) ApplyPatch(patch *JSON) error {
+	ops, err := parsePatch(patch)
+	if err != nil {
+		return err
+	}
+
+	nodes := []*JSON{g}
+	if g.results != nil {
+		nodes = g.results.nodes
+	}
+	raws := make([][]byte, len(nodes))
+	for i, js := range nodes {
+		doc := &JSON{raw: js.getRaw()}
+		for j, op := range ops {
+			if err = op.apply(doc); err != nil {
+				return &PatchError{Index: j, Op: op.op, Path: op.path, Err: err}
+			}
+		}
+		raws[i] = doc.getRaw()
+	}
+	for i, js := range nodes {
+		js.reset(raws[i])
+	}
+	return nil
+}
+
+// parsePatch解析patch中的所有操作，格式错误时返回*PatchError。
+func parsePatch(patch *JSON) ([]patchOp, error) {
+	if patch == nil || !patch.IsArray() || !patch.asArray() {
+		return nil, errors.New("ejson: JSON patch must be an array")
+	}
+
+	ops := make([]patchOp, len(patch.array.values))
+	for i, v := range patch.array.values {
+		op := &ops[i]
+		if !v.IsObject() || !v.asObject() {
+			return nil, &PatchError{Index: i, Err: errors.New("operation must be an object")}
+		}
+		member := func(name string, required bool) (*JSON, error) {
+			js := v.object.entry[name]
+			if js == nil && required {
+				return nil, fmt.Errorf("missing %q", name)
+			}
+			return js, nil
+		}
+		str := func(name string, dst *string) error {
+			js, err := member(name, true)
+			if err != nil {
+				return err
+			}
+			if !js.IsStr() {
+				return fmt.Errorf("%q must be a string", name)
+			}
+			*dst = js.Str()
+			return nil
+		}
+
+		err := str("op", &op.op)
+		if err == nil {
+			err = str("path", &op.path)
+		}
+		if err == nil {
+			switch op.op {
+			case "add", "replace", "test":
+				op.value, err = member("value", true)
+			case "move", "copy":
+				err = str("from", &op.from)
+			case "remove":
+			default:
+				err = fmt.Errorf("unknown op %q", op.op)
+			}
+		}
+		if err == nil {
+			_, err = splitPointer(op.path)
+		}
+		if err == nil {
+			_, err = splitPointer(op.from)
+		}
+		if err != nil {
+			return nil, &PatchError{Index: i, Op: op.op, Path: op.path, Err: err}
+		}
+	}
+	return ops, nil
+}
+
+func (op patchOp) apply(doc *JSON) error {
+	switch op.op {
+	case "add":
+		return patchAdd(doc, op.path, op.value.getRaw())
+
+	case "remove":
+		return doc.RemovePointer(op.path)
+
+	case "replace":
+		js, err := patchValue(doc, op.path)
+		if err != nil {
+			return err
+		}
+		js.reset(op.value.getRaw())
+		return nil
+
+	case "move":
+		if op.from == op.path {
+			_, err := patchValue(doc, op.from)
+			return err
+		}
+		if strings.HasPrefix(op.path, op.from+"/") {
+			return fmt.Errorf("cannot move %q into its child", op.from)
+		}
+		js, err := patchValue(doc, op.from)
+		if err != nil {
+			return err
+		}
+		raw := js.getRaw()
+		if err = doc.RemovePointer(op.from); err != nil {
+			return err
+		}
+		return patchAdd(doc, op.path, raw)
+
+	case "copy":
+		js, err := patchValue(doc, op.from)
+		if err != nil {
+			return err
+		}
+		return patchAdd(doc, op.path, js.getRaw())
+
+	case "test":
+		js, err := patchValue(doc, op.path)
+		if err != nil {
+			return err
+		}
+		if !jsonEqual(js, op.value) {
+			return fmt.Errorf("test failed: %s, want %s", js.getRaw(), op.value.getRaw())
+		}
+	}
+	return nil
+}
+
+// patchValue返回ptr对应的值，值不存在时返回*PointerError。
+func patchValue(doc *JSON, ptr string) (*JSON, error) {
+	tokens, _ := splitPointer(ptr)
+	js, err := pointerNode(doc, ptr, tokens)
+	if err != nil {
+		return nil, err
+	}
+	if !js.Exists() {
+		var tok string
+		if len(tokens) > 0 {
+			tok = tokens[len(tokens)-1]
+		}
+		return nil, &PointerError{Pointer: ptr, Token: tok, Reason: "not found"}
+	}
+	return js, nil
+}
+
+// patchAdd按RFC 6902 add写入raw：ptr的父节点必须是已存在的object或array，
+// 写入array时插入到下标位置，"-"表示追加。
+func patchAdd(doc *JSON, ptr string, raw []byte) error {
+	tokens, _ := splitPointer(ptr)
+	if len(tokens) == 0 {
+		doc.reset(raw)
+		return nil
+	}
+	parent, err := pointerNode(doc, ptr, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+
+	tok := tokens[len(tokens)-1]
+	switch {
+	case parent.IsObject() && parent.asObject():
+		parent.object.Index(tok).reset(raw)
+	case parent.IsArray() && parent.asArray():
+		i := len(parent.array.values)
+		if tok != "-" {
+			idx, ok := pointerIndex(tok)
+			if !ok {
+				return &PointerError{Pointer: ptr, Token: tok, Reason: "invalid array index"}
+			}
+			if idx > i {
+				return &PointerError{Pointer: ptr, Token: tok, Reason: "array index out of range"}
+			}
+			i = idx
+		}
+		parent.spliceRaw(i, 0, []json.RawMessage{raw})
+	case parent.Exists():
+		return &PointerError{Pointer: ptr, Token: tok, Reason: "parent is not an object or array"}
+	default:
+		return &PointerError{Pointer: ptr, Token: tok, Reason: "parent not found"}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package ejson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	// RFC 6902 Appendix A
+	cases := []struct {
+		doc, patch, want string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{`{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+		{`{"foo":{"foo":1,"bar":2}}`, `[{"op":"test","path":"/foo","value":{"bar":2,"foo":1}}]`, `{"foo":{"foo":1,"bar":2}}`},
+
+		{`{"a":[1]}`, `[{"op":"copy","from":"/a","path":"/b"},{"op":"add","path":"/b/0","value":0}]`, `{"a":[1],"b":[0,1]}`},
+		{`{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+		{`{"a":1}`, `[{"op":"add","path":"","value":{"b":2}}]`, `{"b":2}`},
+		{`{"a":1}`, `[{"op":"move","from":"/a","path":"/a"}]`, `{"a":1}`},
+		{`{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a~1b"}]`, `{"a/b":{"b":1}}`},
+		{``, `[{"op":"add","path":"","value":1}]`, `1`},
+	}
+	for _, c := range cases {
+		g := FromString(c.doc)
+		if err := g.ApplyPatch(FromString(c.patch)); err != nil {
+			t.Fatalf("apply %s to %s: %v", c.patch, c.doc, err)
+		}
+		if s := g.UnsafeString(); s != c.want {
+			t.Fatalf("apply %s to %s: %s, should be %s", c.patch, c.doc, s, c.want)
+		}
+	}
+}
+
+func TestApplyPatchError(t *testing.T) {
+	cases := []struct {
+		doc, patch string
+		index      int
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, 0},
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, 0},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/1","value":1},{"op":"add","path":"/foo/3","value":2}]`, 1},
+		{`{"foo":["bar"]}`, `[{"op":"remove","path":"/foo/-"}]`, 0},
+		{`{"foo":1}`, `[{"op":"replace","path":"/bar","value":1}]`, 0},
+		{`{"foo":1}`, `[{"op":"remove","path":""}]`, 0},
+		{`{"foo":{}}`, `[{"op":"move","from":"/foo","path":"/foo/bar"}]`, 0},
+		{`{"foo":1}`, `[{"op":"copy","from":"/bar","path":"/baz"}]`, 0},
+		{`{"foo":1}`, `[{"op":"add","path":"/foo/bar","value":1}]`, 0},
+		{`{"foo":[]}`, `[{"op":"add","path":"/foo/01","value":1}]`, 0},
+		{`{"foo":1}`, `[{"op":"test","path":"/foo","value":1},{"op":"move","path":"/bar"}]`, 1},
+		{`{"foo":1}`, `[{"op":"add","path":"/bar"}]`, 0},
+		{`{"foo":1}`, `[{"op":"nop","path":"/foo"}]`, 0},
+		{`{"foo":1}`, `[{"op":"add","path":"foo","value":1}]`, 0},
+		{`{"foo":1}`, `[{"op":"remove","path":"/foo"},1]`, 1},
+	}
+	for _, c := range cases {
+		g := FromString(c.doc)
+		err := g.ApplyPatch(FromString(c.patch))
+		var pe *PatchError
+		if !errors.As(err, &pe) {
+			t.Fatalf("apply %s to %s: %v, should be *PatchError", c.patch, c.doc, err)
+		}
+		if pe.Index != c.index {
+			t.Fatalf("apply %s to %s: %v, index should be %v", c.patch, c.doc, err, c.index)
+		}
+		if s := g.UnsafeString(); s != c.doc {
+			t.Fatalf("apply %s to %s: document changed to %s", c.patch, c.doc, s)
+		}
+	}
+
+	if err := FromString(`{}`).ApplyPatch(FromString(`{}`)); err == nil {
+		t.Fatal("patch must be an array")
+	}
+}
+
+func TestApplyPatchAtomic(t *testing.T) {
+	g := FromString(`{"a":{"b":1},"c":[1,2]}`)
+	b := g.Get("a.b")
+	err := g.ApplyPatch(FromString(`[
+		{"op":"replace","path":"/a/b","value":2},
+		{"op":"remove","path":"/c/0"},
+		{"op":"test","path":"/c/0","value":1}
+	]`))
+	if err == nil {
+		t.Fatal("test should fail")
+	}
+	if s := g.String(); s != `{"a":{"b":1},"c":[1,2]}` {
+		t.Fatalf("document changed: %s", s)
+	}
+	b.Set(3)
+	if s := g.String(); s != `{"a":{"b":3},"c":[1,2]}` {
+		t.Fatalf("node should remain in tree: %s", s)
+	}
+
+	g = FromString(`{"items":[{"a":1},{"a":2},{"b":3}]}`)
+	patch := FromString(`[{"op":"replace","path":"/a","value":0}]`)
+	if err := g.Get("items[*]").ApplyPatch(patch); err == nil {
+		t.Fatal("replace missing value should fail")
+	}
+	if s := g.String(); s != `{"items":[{"a":1},{"a":2},{"b":3}]}` {
+		t.Fatalf("results changed: %s", s)
+	}
+	if err := g.Get("items[:2]").ApplyPatch(patch); err != nil {
+		t.Fatal(err)
+	}
+	if s := g.String(); s != `{"items":[{"a":0},{"a":0},{"b":3}]}` {
+		t.Fatalf("apply to results: %s", s)
+	}
+}
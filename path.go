@@ -0,0 +1,81 @@
+package ejson
+
+// Path是预编译的smart key，可以在多个goroutine间共享。
+// 对频繁使用的固定smart key，预编译可以省去每次调用时的解析开销。
+type Path struct {
+	key  string
+	segs []segment
+}
+
+// Compile解析smartKey，返回可复用的*Path。
+func Compile(smartKey string) (*Path, error) {
+	segs, err := parseSmartKey(smartKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Path{key: smartKey, segs: segs}, nil
+}
+
+// MustCompile与Compile相同，但smartKey不合法时panic，适用于初始化全局变量。
+func MustCompile(smartKey string) *Path {
+	p, err := Compile(smartKey)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// WithKeyMatch返回按m匹配key的*Path，p本身不变。
+// 查找时使用m和json上SetKeyMatch设置的匹配方式中较宽松的一个，如：
+//
+//	ejson.MustCompile("data.userId").WithKeyMatch(ejson.MatchNormalize).Get(js) // 匹配user_id、UserID等
+func (p *Path) WithKeyMatch(m KeyMatch) *Path {
+	return &Path{key: p.key, segs: withMatch(p.segs, m)}
+}
+
+// String返回编译前的smart key。
+func (p *Path) String() string {
+	return p.key
+}
+
+// Get与(*JSON).Get相同，返回js中路径p对应的值。
+func (p *Path) Get(js *JSON) *JSON {
+	return get(js, p.segs)
+}
+
+// GetAll与(*JSON).GetAll相同，返回js中路径p匹配到的所有值。
+func (p *Path) GetAll(js *JSON) *Results {
+	return getAll(js, p.segs)
+}
+
+// Lookup与(*JSON).Lookup相同，以只读方式查询js中路径p对应的值。
+func (p *Path) Lookup(js *JSON) *JSON {
+	return lookupJSON(js, p.segs)
+}
+
+// Set将js中路径p对应的值设置为v，路径不存在时自动创建。
+func (p *Path) Set(js *JSON, v any) error {
+	return p.Get(js).Set(v)
+}
+
+// Remove将js中路径p对应的值移除，路径不存在时不做任何操作。
+func (p *Path) Remove(js *JSON) {
+	if !p.Exists(js) {
+		return
+	}
+	p.Get(js).Remove()
+}
+
+// Exists返回js中路径p对应的值是否存在，不会在json树上创建节点。
+// 路径包含通配符等多值片段时，存在任一匹配值即返回true。
+func (p *Path) Exists(js *JSON) bool {
+	if js.results != nil || fanout(p.segs) {
+		for _, c := range lookupCursors(js, p.segs) {
+			if c.exists() {
+				return true
+			}
+		}
+		return false
+	}
+	return len(lookup(js, p.segs)) > 0
+}
@@ -0,0 +1,89 @@
+package ejson
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	if _, err := Compile("a.[0]"); err == nil {
+		t.Fatal("a.[0] should be invalid")
+	}
+
+	p, err := Compile("a[0].b")
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if p.String() != "a[0].b" {
+		t.Fatalf("path string: %v", p)
+	}
+
+	g := FromString(`{"a":[{"b":1}]}`)
+	if v := p.Get(g).Int(); v != 1 {
+		t.Fatalf("a[0].b: %v", v)
+	}
+	if v := p.Lookup(g).Int(); v != 1 {
+		t.Fatalf("lookup a[0].b: %v", v)
+	}
+	if !p.Exists(g) {
+		t.Fatal("a[0].b should exists")
+	}
+	p.Set(g, 2)
+	if s := g.UnsafeString(); s != `{"a":[{"b":2}]}` {
+		t.Fatalf("set: %s", s)
+	}
+	p.Remove(g)
+	if s := g.UnsafeString(); s != `{"a":[{}]}` {
+		t.Fatalf("remove: %s", s)
+	}
+	if p.Exists(g) {
+		t.Fatal("a[0].b should not exists")
+	}
+	p.Remove(g)
+	if s := g.UnsafeString(); s != `{"a":[{}]}` {
+		t.Fatalf("remove again: %s", s)
+	}
+}
+
+func TestMustCompile(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("must compile should panic")
+		}
+	}()
+	MustCompile("a[x]")
+}
+
+func TestPathConcurrent(t *testing.T) {
+	p := MustCompile("a.b[1]")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			g := FromString(`{"a":{"b":[0,0]}}`)
+			p.Set(g, i)
+			if v := p.Get(g).Int(); v != int64(i) {
+				t.Errorf("a.b[1]: %v", v)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkPathGet(b *testing.B) {
+	p := MustCompile("a.b[1].c")
+	g := FromString(`{"a":{"b":[0,{"c":1}]}}`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p.Get(g)
+	}
+}
+
+func BenchmarkSmartKeyGet(b *testing.B) {
+	g := FromString(`{"a":{"b":[0,{"c":1}]}}`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g.Get("a.b[1].c")
+	}
+}
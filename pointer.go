@@ -0,0 +1,216 @@
+package ejson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PointerError表示JSON Pointer不合法或无法作用于当前json。
+type PointerError struct {
+	Pointer string // 完整的JSON Pointer
+	Token   string // 出错的reference token，经过~0、~1反转义
+	Reason  string
+}
+
+func (e *PointerError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("ejson: JSON pointer %q: %v", e.Pointer, e.Reason)
+	}
+	return fmt.Sprintf("ejson: JSON pointer %q at %q: %v", e.Pointer, e.Token, e.Reason)
+}
+
+// splitPointer按RFC 6901将ptr拆分为reference token，并处理~1、~0转义。
+// ""表示整个文档，返回空切片。
+func splitPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, &PointerError{Pointer: ptr, Reason: "must start with '/'"}
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, tok := range tokens {
+		if strings.IndexByte(tok, '~') < 0 {
+			continue
+		}
+		for j := 0; j < len(tok); j++ {
+			if tok[j] == '~' && (j+1 == len(tok) || (tok[j+1] != '0' && tok[j+1] != '1')) {
+				return nil, &PointerError{Pointer: ptr, Token: tok, Reason: "invalid escape, '~' must be followed by '0' or '1'"}
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// escapePointerToken按RFC 6901转义reference token。
+func escapePointerToken(tok string) string {
+	if strings.IndexAny(tok, "~/") < 0 {
+		return tok
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(tok, "~", "~0"), "/", "~1")
+}
+
+// pointerIndex解析数组下标，RFC 6901只允许不带前导0的非负整数。
+func pointerIndex(tok string) (int, bool) {
+	if tok == "" || len(tok) > 1 && tok[0] == '0' {
+		return 0, false
+	}
+	for i := 0; i < len(tok); i++ {
+		if tok[i] < '0' || tok[i] > '9' {
+			return 0, false
+		}
+	}
+	idx, err := strconv.Atoi(tok)
+	return idx, err == nil
+}
+
+// pointerNode按tokens逐层查找值，不存在的值和get一样在写入时自动创建。
+// 数组上的"-"表示最后一个元素之后的位置，写入即追加。
+// 经过已存在的非object、array值时返回*PointerError，不会把它替换为object。
+func pointerNode(g *JSON, ptr string, tokens []string) (*JSON, error) {
+	for _, tok := range tokens {
+		if !g.IsArray() {
+			if g.Exists() && !g.IsObject() {
+				return nil, &PointerError{Pointer: ptr, Token: tok, Reason: fmt.Sprintf("cannot index %v", g.Kind())}
+			}
+			g = g.ObjectIndex(tok)
+			continue
+		}
+		n := g.Len()
+		if tok == "-" {
+			g = g.ArrayIndex(n)
+			continue
+		}
+		idx, ok := pointerIndex(tok)
+		if !ok {
+			return nil, &PointerError{Pointer: ptr, Token: tok, Reason: "invalid array index"}
+		}
+		if idx > n {
+			return nil, &PointerError{Pointer: ptr, Token: tok, Reason: "array index out of range"}
+		}
+		g = g.ArrayIndex(idx)
+	}
+	return g, nil
+}
+
+// GetPointer返回RFC 6901 JSON Pointer对应的值，如"/a/0/b~1c"。
+// 和Get一样，值不存在时返回的*JSON不为空，写入后将写入json树；
+// 数组上的"-"表示最后一个元素之后的位置，如GetPointer("/items/-").Set(v)将v追加到items。
+// ptr不合法时返回的*JSON不存在，严格模式下将panic，见SetStrict。
+func (g *JSON) GetPointer(ptr string) *JSON {
+	tokens, err := splitPointer(ptr)
+	if err == nil {
+		var js *JSON
+		if js, err = pointerNode(g, ptr, tokens); err == nil {
+			return js
+		}
+	}
+	if strict.Load() {
+		panic(err)
+	}
+	return new(JSON)
+}
+
+// SetPointer将JSON Pointer对应的值设置为v，中间不存在的值将作为object自动创建，
+// 中间的值已存在但不是object、array(如null、数字、字符串)时返回*PointerError。
+// 数组上的"-"或等于数组长度的下标表示追加，大于数组长度的下标返回*PointerError。
+func (g *JSON) SetPointer(ptr string, v any) error {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return err
+	}
+	js, err := pointerNode(g, ptr, tokens)
+	if err != nil {
+		return err
+	}
+	return js.Set(v)
+}
+
+// RemovePointer移除JSON Pointer对应的值，值不存在时返回*PointerError。
+func (g *JSON) RemovePointer(ptr string) error {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return &PointerError{Pointer: ptr, Reason: "cannot remove the whole document"}
+	}
+	js, err := pointerNode(g, ptr, tokens)
+	if err != nil {
+		return err
+	}
+	if !js.Exists() {
+		return &PointerError{Pointer: ptr, Token: tokens[len(tokens)-1], Reason: "not found"}
+	}
+	js.Remove()
+	return nil
+}
+
+// PointerToSmartKey将JSON Pointer转为等价的smart key，如"/a/0/b~1c"转为"a.0.b/c"。
+// 需要转义的token按QuoteKey加引号；"01"这类不能作为数组下标的数字token同样加引号，
+// 以免smart key把它当作下标。最后一个token是"-"时转为"[+]"，与GetPointer一样写入时追加到数组末尾，
+// 如"/items/-"转为"items[+]"；其它位置的"-"加引号作为普通key。
+// 表示整个文档的""没有对应的smart key，返回*PointerError。
+func PointerToSmartKey(ptr string) (string, error) {
+	tokens, err := splitPointer(ptr)
+	if err != nil {
+		return "", err
+	}
+	if len(tokens) == 0 {
+		return "", &PointerError{Pointer: ptr, Reason: "the whole document has no smart key"}
+	}
+	var b strings.Builder
+	for i, tok := range tokens {
+		if tok == "-" && i == len(tokens)-1 {
+			b.WriteString("[+]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		if tok == "-" {
+			b.WriteString(`"-"`)
+			continue
+		}
+		if _, index := pointerIndex(tok); !index && keySegment(tok).numeric {
+			b.WriteString(`"` + tok + `"`)
+			continue
+		}
+		b.WriteString(QuoteKey(tok))
+	}
+	return b.String(), nil
+}
+
+// SmartKeyToPointer将smart key转为等价的JSON Pointer，如"a[0].b/c"转为"/a/0/b~1c"，"a[+]"转为"/a/-"。
+// 只能转换指向固定位置的smart key：含通配符、"..key"、过滤条件、切片、路径函数、"(a|b)"、"[-]"或负数下标时返回*PathError。
+func SmartKeyToPointer(smartKey string) (string, error) {
+	segs, err := parseSmartKey(smartKey)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, seg := range segs {
+		if seg.fanout() {
+			return "", &PathError{Key: smartKey, Segment: smartKey, Reason: "matches multiple values, no JSON pointer"}
+		}
+		if seg.kind == segAppend {
+			b.WriteString("/-")
+			continue
+		}
+		if seg.kind != segKey && seg.kind != segIndex {
+			return "", &PathError{Key: smartKey, Segment: smartKey, Reason: "no JSON pointer for this segment"}
+		}
+		b.WriteByte('/')
+		if seg.kind == segIndex {
+			if seg.index < 0 {
+				return "", &PathError{Key: smartKey, Segment: smartKey, Reason: "negative index has no JSON pointer"}
+			}
+			b.WriteString(strconv.Itoa(seg.index))
+			continue
+		}
+		b.WriteString(escapePointerToken(seg.key))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,166 @@
+package ejson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetPointer(t *testing.T) {
+	// RFC 6901 section 5
+	g := FromString(`{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}`)
+	cases := map[string]string{
+		"":       g.String(),
+		"/foo":   `["bar","baz"]`,
+		"/foo/0": `"bar"`,
+		"/":      `0`,
+		"/a~1b":  `1`,
+		"/c%d":   `2`,
+		"/e^f":   `3`,
+		"/g|h":   `4`,
+		"/i\\j":  `5`,
+		"/k\"l":  `6`,
+		"/ ":     `7`,
+		"/m~0n":  `8`,
+	}
+	for ptr, want := range cases {
+		if s := g.GetPointer(ptr).UnsafeString(); s != want {
+			t.Fatalf("get pointer %q: %s, should be %s", ptr, s, want)
+		}
+	}
+
+	for _, ptr := range []string{"/foo/2", "/foo/-", "/foo/01", "/foo/a", "/x/y", "foo", "/m~2n", "/m~"} {
+		if g.GetPointer(ptr).Exists() {
+			t.Fatalf("pointer %q should not exists", ptr)
+		}
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	g := FromString(`{"a":[1,2],"b":{"c":1}}`)
+	sets := []struct {
+		ptr  string
+		val  any
+		want string
+	}{
+		{"/a/0", 0, `{"a":[0,2],"b":{"c":1}}`},
+		{"/a/-", 3, `{"a":[0,2,3],"b":{"c":1}}`},
+		{"/a/3", 4, `{"a":[0,2,3,4],"b":{"c":1}}`},
+		{"/b/c", "x", `{"a":[0,2,3,4],"b":{"c":"x"}}`},
+		{"/b/d~1e/f", true, `{"a":[0,2,3,4],"b":{"c":"x","d/e":{"f":true}}}`},
+		{"/b/0", 0, `{"a":[0,2,3,4],"b":{"c":"x","d/e":{"f":true},"0":0}}`},
+	}
+	for _, c := range sets {
+		if err := g.SetPointer(c.ptr, c.val); err != nil {
+			t.Fatalf("set pointer %q: %v", c.ptr, err)
+		}
+		if s := g.String(); s != c.want {
+			t.Fatalf("set pointer %q: %s, should be %s", c.ptr, s, c.want)
+		}
+	}
+
+	g = FromString(`{"a":[]}`)
+	g.GetPointer("/a/-").Set(1)
+	if s := g.String(); s != `{"a":[1]}` {
+		t.Fatalf("get pointer append: %s", s)
+	}
+
+	var pe *PointerError
+	for _, ptr := range []string{"/a/5", "/a/01", "/a/-1", "a", "/~x"} {
+		if err := g.SetPointer(ptr, 0); !errors.As(err, &pe) {
+			t.Fatalf("set pointer %q: %v", ptr, err)
+		}
+	}
+	if s := g.String(); s != `{"a":[1]}` {
+		t.Fatalf("invalid set pointer modified json: %s", s)
+	}
+
+	// 不会把已存在的非object、array值替换为object
+	const doc = `{"a":1,"s":"x","n":null,"l":[1]}`
+	g = FromString(doc)
+	for _, ptr := range []string{"/a/b", "/s/0", "/n/b", "/l/0/b"} {
+		if err := g.SetPointer(ptr, 2); !errors.As(err, &pe) {
+			t.Fatalf("set pointer through scalar %q: %v", ptr, err)
+		}
+		if g.GetPointer(ptr).Exists() {
+			t.Fatalf("get pointer through scalar %q should not exists", ptr)
+		}
+	}
+	if s := g.String(); s != doc {
+		t.Fatalf("set pointer through scalar modified json: %s", s)
+	}
+}
+
+func TestRemovePointer(t *testing.T) {
+	g := FromString(`{"a":[1,2,3],"b":{"c~":1,"d":2}}`)
+	for _, ptr := range []string{"/a/1", "/b/c~0"} {
+		if err := g.RemovePointer(ptr); err != nil {
+			t.Fatalf("remove pointer %q: %v", ptr, err)
+		}
+	}
+	if s := g.String(); s != `{"a":[1,3],"b":{"d":2}}` {
+		t.Fatalf("remove pointer: %s", s)
+	}
+	for _, ptr := range []string{"", "/a/2", "/a/-", "/x", "/b/d/e"} {
+		if err := g.RemovePointer(ptr); err == nil {
+			t.Fatalf("remove pointer %q should fail", ptr)
+		}
+	}
+}
+
+func TestPointerSmartKey(t *testing.T) {
+	cases := []struct {
+		ptr, key string
+	}{
+		{"/a/0/b~1c", "a.0.b/c"},
+		{"/a.b/c~0d", `"a.b".c~d`},
+		{"/", `""`},
+		{"/a/01", `a."01"`},
+		{"/a/-", `a[+]`},
+		{"/-", `[+]`},
+		{"/a/-/b", `a."-".b`},
+		{"/*", `"*"`},
+	}
+	for _, c := range cases {
+		key, err := PointerToSmartKey(c.ptr)
+		if err != nil || key != c.key {
+			t.Fatalf("pointer %q to smart key: %q, %v, should be %q", c.ptr, key, err, c.key)
+		}
+		ptr, err := SmartKeyToPointer(key)
+		if err != nil || ptr != c.ptr {
+			t.Fatalf("smart key %q to pointer: %q, %v, should be %q", key, ptr, err, c.ptr)
+		}
+	}
+
+	if ptr, err := SmartKeyToPointer("[0].a[1]"); err != nil || ptr != "/0/a/1" {
+		t.Fatalf("smart key to pointer: %q, %v", ptr, err)
+	}
+	if _, err := PointerToSmartKey(""); err == nil {
+		t.Fatal("whole document should have no smart key")
+	}
+	for _, key := range []string{"a[*]", "..a", "a[-1]", "a[?@.b]", "a["} {
+		if _, err := SmartKeyToPointer(key); err == nil {
+			t.Fatalf("smart key %q should have no pointer", key)
+		}
+	}
+
+	// "-"转换前后同样追加到数组末尾
+	g := FromString(`{"items":[1,2,3]}`)
+	key, _ := PointerToSmartKey("/items/-")
+	g.Get(key).Set(4)
+	g.SetPointer("/items/-", 5)
+	if s := g.String(); s != `{"items":[1,2,3,4,5]}` {
+		t.Fatalf("append through smart key: %s", s)
+	}
+	if ptr, _ := SmartKeyToPointer(key); ptr != "/items/-" {
+		t.Fatalf("round trip: %q", ptr)
+	}
+
+	// 转换前后指向同一个值
+	g = FromString(`{"a":[{"01":1,"1":2},{"01":3}],"b/c":{"x.y":4}}`)
+	for _, ptr := range []string{"/a/1/01", "/a/0/1", "/b~1c/x.y"} {
+		key, _ := PointerToSmartKey(ptr)
+		if a, b := g.GetPointer(ptr).String(), g.Get(key).String(); a != b {
+			t.Fatalf("pointer %q: %s, smart key %q: %s", ptr, a, key, b)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ejson
+
+import "bytes"
+
+// Results是smart key中通配符等匹配到的一组值，每个值都是json树上的节点。
+// 结果中可能包含不存在的值(Exists()返回false)，如"items[*].id"中没有id的元素，
+// 对这些值Set将在json树中创建它们。
+type Results struct {
+	nodes []*JSON
+}
+
+// GetAll返回smartKey匹配到的所有值，smartKey语法见Get。
+// 不含通配符等多值片段的smartKey，返回只有一个值的结果集。
+func (g *JSON) GetAll(smartKey string) *Results {
+	segs, ok := parseKey(smartKey)
+	if !ok {
+		return new(Results)
+	}
+	return getAll(g, segs)
+}
+
+// get返回g中segs对应的值。segs包含通配符等多值片段时，返回的*JSON代表整个结果集：
+// 读取时是由所有匹配值组成的数组，Set、Remove将作用于每个匹配值。
+func get(g *JSON, segs []segment) *JSON {
+	if g.results == nil && !fanout(segs) {
+		for _, s := range segs {
+			g = s.get(g)
+		}
+		return g
+	}
+	nodes, multi := getNodes(g, segs)
+	if !multi {
+		return nodes[0]
+	}
+	return &JSON{results: &Results{nodes: nodes}}
+}
+
+func getAll(g *JSON, segs []segment) *Results {
+	nodes, _ := getNodes(g, segs)
+	return &Results{nodes: nodes}
+}
+
+// getNodes返回segs匹配到的所有值，multi表示结果是否是多值结果集。
+// 路径函数的输入是它前面的路径对应的值，前面的路径匹配多个值时输入是整个结果集，函数返回单个值。
+func getNodes(g *JSON, segs []segment) (nodes []*JSON, multi bool) {
+	nodes = []*JSON{g}
+	if g.results != nil {
+		nodes = g.results.nodes
+		multi = true
+	}
+	for _, s := range segs {
+		if s.kind == segFunc {
+			in := nodes[0]
+			if multi {
+				in = &JSON{results: &Results{nodes: nodes}}
+			}
+			if out := s.apply(in); out.results != nil {
+				nodes, multi = out.results.nodes, true
+			} else {
+				nodes, multi = []*JSON{out}, false
+			}
+			continue
+		}
+		multi = multi || s.fanout()
+		next := make([]*JSON, 0, len(nodes))
+		for _, n := range nodes {
+			next = s.appendAll(next, n)
+		}
+		nodes = next
+	}
+	return nodes, multi
+}
+
+// each对每个匹配值调用fn，返回所有结果组成的结果集。
+func (r *Results) each(fn func(js *JSON) *JSON) *JSON {
+	nodes := make([]*JSON, len(r.nodes))
+	for i, js := range r.nodes {
+		nodes[i] = fn(js)
+	}
+	return &JSON{results: &Results{nodes: nodes}}
+}
+
+// Len返回匹配到的值的个数。
+func (r *Results) Len() int {
+	return len(r.nodes)
+}
+
+// Index返回第i个匹配值，负数从尾部计数。i越界时返回的*JSON不存在。
+func (r *Results) Index(i int) *JSON {
+	if i, ok := normIndex(i, len(r.nodes)); ok {
+		return r.nodes[i]
+	}
+	return new(JSON)
+}
+
+// Nodes返回所有匹配值。
+func (r *Results) Nodes() []*JSON {
+	nodes := make([]*JSON, len(r.nodes))
+	copy(nodes, r.nodes)
+	return nodes
+}
+
+// Each按顺序遍历匹配值，fn返回false时停止遍历。
+func (r *Results) Each(fn func(i int, js *JSON) bool) {
+	for i, js := range r.nodes {
+		if !fn(i, js) {
+			return
+		}
+	}
+}
+
+// Exists返回是否存在任一匹配值。
+func (r *Results) Exists() bool {
+	for _, js := range r.nodes {
+		if js.Exists() {
+			return true
+		}
+	}
+	return false
+}
+
+// Get在每个匹配值上继续查询smartKey，返回所有结果。
+func (r *Results) Get(smartKey string) *Results {
+	return (&JSON{results: r}).GetAll(smartKey)
+}
+
+// Set将每个匹配值设置为v。
+func (r *Results) Set(v any) error {
+	raw, err := marshal(v)
+	if err != nil {
+		return err
+	}
+	for _, js := range r.nodes {
+		js.reset(raw)
+	}
+	return nil
+}
+
+// Remove将每个匹配值从json树中移除。
+func (r *Results) Remove() {
+	for _, js := range r.nodes {
+		js.Remove()
+	}
+}
+
+// MarshalJSON实现json.Marshaler接口，返回由所有匹配值组成的数组。
+func (r *Results) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, js := range r.nodes {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		raw, _ := js.MarshalJSON()
+		buf.Write(raw)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// String实现fmt.Stringer接口，返回由所有匹配值组成的json数组。
+func (r *Results) String() string {
+	raw, _ := r.MarshalJSON()
+	return string(raw)
+}
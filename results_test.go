@@ -0,0 +1,135 @@
+package ejson
+
+import "testing"
+
+func TestWildcard(t *testing.T) {
+	g := FromString(`{"items":[{"id":1},{"id":2},{"x":3}],"users":{"a":{"email":"a@x"},"b":{"email":"b@x"}}}`)
+	cases := map[string]string{
+		"items[*].id":   `[1,2,null]`,
+		"items.*.id":    `[1,2,null]`,
+		"users.*.email": `["a@x","b@x"]`,
+		"users[*]":      `[{"email":"a@x"},{"email":"b@x"}]`,
+		"*.a.email":     `[null,"a@x"]`,
+		"items[0].*":    `[1]`,
+		"items[0].id.*": `[]`,
+		"x[*]":          `[]`,
+	}
+	for key, want := range cases {
+		if s := g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get %v: %s, should be %s", key, s, want)
+		}
+		if s := g.Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup %v: %s, should be %s", key, s, want)
+		}
+		if s := g.GetMany(key)[0].UnsafeString(); s != want {
+			t.Fatalf("get many %v: %s, should be %s", key, s, want)
+		}
+		if s := g.GetAll(key).String(); s != want {
+			t.Fatalf("get all %v: %s, should be %s", key, s, want)
+		}
+	}
+
+	if g.Get("x[*]").Exists() {
+		t.Fatal("x[*] should not exists")
+	}
+	if n := g.Get("items[*]").Len(); n != 3 {
+		t.Fatalf("items[*] len: %v", n)
+	}
+	if id := g.Get("items[*].id").ArrayIndex(1).Int(); id != 2 {
+		t.Fatalf("items[*].id[1]: %v", id)
+	}
+	if s := g.Get("items[*]").Get("id").UnsafeString(); s != `[1,2,null]` {
+		t.Fatalf("items[*] get id: %v", s)
+	}
+
+	if FromString(`{"*":1,"a":2}`).Get(`"*"`).Int() != 1 {
+		t.Fatal(`quoted "*" should be a key`)
+	}
+	if FromString(`{"*":1,"\\*":2}`).Get(`\*`).Int() != 2 {
+		t.Fatal(`unquoted \* should be the key \*`)
+	}
+}
+
+func TestWildcardSet(t *testing.T) {
+	g := FromString(`{"items":[{"id":1},{"id":2},{}]}`)
+	g.Get("items[*].ok").Set(true)
+	if s := g.UnsafeString(); s != `{"items":[{"id":1,"ok":true},{"id":2,"ok":true},{"ok":true}]}` {
+		t.Fatalf("set: %s", s)
+	}
+
+	g.Get("items[*].id").Remove()
+	if s := g.UnsafeString(); s != `{"items":[{"ok":true},{"ok":true},{"ok":true}]}` {
+		t.Fatalf("remove: %s", s)
+	}
+
+	g = FromString(`{"a":[1,2,3],"b":"[4,5]"}`)
+	g.Get("a[*]").Remove()
+	g.Get("b[*]").Set(0)
+	if s := g.UnsafeString(); s != `{"a":[],"b":"[0,0]"}` {
+		t.Fatalf("remove all: %s", s)
+	}
+}
+
+func TestResults(t *testing.T) {
+	g := FromString(`{"users":[{"name":"a"},{"name":"b"}]}`)
+	r := g.GetAll("users[*].name")
+	if r.Len() != 2 || !r.Exists() {
+		t.Fatalf("results len: %v", r.Len())
+	}
+	var names []string
+	r.Each(func(i int, js *JSON) bool {
+		names = append(names, js.Str())
+		return true
+	})
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Fatalf("results each: %v", names)
+	}
+	if r.Index(-1).Str() != "b" || r.Index(2).Exists() {
+		t.Fatal("results index")
+	}
+
+	r.Index(0).Set("c")
+	if s := g.UnsafeString(); s != `{"users":[{"name":"c"},{"name":"b"}]}` {
+		t.Fatalf("results node set: %s", s)
+	}
+
+	users := g.GetAll("users[*]")
+	users.Get("age").Set(18)
+	if s := g.UnsafeString(); s != `{"users":[{"name":"c","age":18},{"name":"b","age":18}]}` {
+		t.Fatalf("results get set: %s", s)
+	}
+
+	single := g.GetAll("users[0].name")
+	if single.Len() != 1 || single.Index(0).Str() != "c" {
+		t.Fatalf("single results: %v", single)
+	}
+
+	p := MustCompile("users[*].age")
+	if !p.Exists(g) || p.GetAll(g).Len() != 2 {
+		t.Fatal("path with wildcard should exists")
+	}
+	p.Remove(g)
+	if p.Exists(g) {
+		t.Fatalf("path remove: %s", g.UnsafeString())
+	}
+}
+
+func TestResultsIndex(t *testing.T) {
+	g := FromString(`{"items":[{"a":1},{"a":2,"s":"{\"x\":1}"}]}`)
+	items := g.Get("items[*]")
+	if s := items.ObjectIndex("a").String(); s != `[1,2]` {
+		t.Fatalf("object index: %s", s)
+	}
+	items.ObjectIndex("x").Set(0)
+	if s := g.String(); s != `{"items":[{"a":1,"x":0},{"a":2,"s":"{\"x\":1}","x":0}]}` {
+		t.Fatalf("object index set: %s", s)
+	}
+
+	g.Get("items[*].s").StrJSON().ObjectIndex("y").Set(true)
+	if s := g.Get("items[1].s").Str(); s != `{"x":1,"y":true}` {
+		t.Fatalf("str json set: %s", s)
+	}
+	if s := g.Get("items[0].s").Str(); s != `{"y":true}` {
+		t.Fatalf("str json create: %s", s)
+	}
+}
@@ -0,0 +1,299 @@
+package ejson
+
+import (
+	"encoding/json"
+)
+
+// maxNestingDepth与encoding/json保持一致，防止恶意构造的深层嵌套耗尽栈空间。
+const maxNestingDepth = 10000
+
+func isSpace(c byte) bool {
+	return c <= ' ' && (c == ' ' || c == '\t' || c == '\r' || c == '\n')
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && isSpace(data[i]) {
+		i++
+	}
+	return i
+}
+
+// scanValue从data[i]开始扫描一个完整的json值，返回值结束位置(不含)。
+// 扫描过程会校验值的合法性，但不会拷贝任何数据。
+func scanValue(data []byte, i int) (int, bool) {
+	return scanValueDepth(data, i, 0)
+}
+
+func scanValueDepth(data []byte, i, depth int) (int, bool) {
+	if i >= len(data) {
+		return i, false
+	}
+	switch c := data[i]; {
+	case c == '"':
+		return scanString(data, i)
+	case c == '{':
+		return scanObjectEnd(data, i, depth+1)
+	case c == '[':
+		return scanArrayEnd(data, i, depth+1)
+	case c == '-' || ('0' <= c && c <= '9'):
+		return scanNumber(data, i)
+	case c == 't':
+		return scanLiteral(data, i, "true")
+	case c == 'f':
+		return scanLiteral(data, i, "false")
+	case c == 'n':
+		return scanLiteral(data, i, "null")
+	}
+	return i, false
+}
+
+func scanLiteral(data []byte, i int, lit string) (int, bool) {
+	if len(data)-i < len(lit) || unsafeString(data[i:i+len(lit)]) != lit {
+		return i, false
+	}
+	return i + len(lit), true
+}
+
+func scanNumber(data []byte, i int) (int, bool) {
+	if data[i] == '-' {
+		i++
+	}
+	if i >= len(data) {
+		return i, false
+	}
+	switch {
+	case data[i] == '0':
+		i++
+	case '1' <= data[i] && data[i] <= '9':
+		for i < len(data) && '0' <= data[i] && data[i] <= '9' {
+			i++
+		}
+	default:
+		return i, false
+	}
+	if i < len(data) && data[i] == '.' {
+		i++
+		start := i
+		for i < len(data) && '0' <= data[i] && data[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return i, false
+		}
+	}
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+		start := i
+		for i < len(data) && '0' <= data[i] && data[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return i, false
+		}
+	}
+	return i, true
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func scanString(data []byte, i int) (int, bool) {
+	i++ // '"'
+	for i < len(data) {
+		c := data[i]
+		switch {
+		case c == '"':
+			return i + 1, true
+		case c == '\\':
+			i++
+			if i >= len(data) {
+				return i, false
+			}
+			switch data[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				i++
+			case 'u':
+				if len(data)-i < 5 {
+					return i, false
+				}
+				for _, h := range data[i+1 : i+5] {
+					if !isHex(h) {
+						return i, false
+					}
+				}
+				i += 5
+			default:
+				return i, false
+			}
+		case c < ' ':
+			return i, false
+		default:
+			i++
+		}
+	}
+	return i, false
+}
+
+func scanObjectEnd(data []byte, i, depth int) (int, bool) {
+	if depth > maxNestingDepth {
+		return i, false
+	}
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return i + 1, true
+	}
+	for {
+		if i >= len(data) || data[i] != '"' {
+			return i, false
+		}
+		var ok bool
+		if i, ok = scanString(data, i); !ok {
+			return i, false
+		}
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != ':' {
+			return i, false
+		}
+		i = skipSpace(data, i+1)
+		if i, ok = scanValueDepth(data, i, depth); !ok {
+			return i, false
+		}
+		i = skipSpace(data, i)
+		if i >= len(data) {
+			return i, false
+		}
+		if data[i] == '}' {
+			return i + 1, true
+		}
+		if data[i] != ',' {
+			return i, false
+		}
+		i = skipSpace(data, i+1)
+	}
+}
+
+func scanArrayEnd(data []byte, i, depth int) (int, bool) {
+	if depth > maxNestingDepth {
+		return i, false
+	}
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == ']' {
+		return i + 1, true
+	}
+	for {
+		var ok bool
+		if i, ok = scanValueDepth(data, i, depth); !ok {
+			return i, false
+		}
+		i = skipSpace(data, i)
+		if i >= len(data) {
+			return i, false
+		}
+		if data[i] == ']' {
+			return i + 1, true
+		}
+		if data[i] != ',' {
+			return i, false
+		}
+		i = skipSpace(data, i+1)
+	}
+}
+
+// scanObject遍历object的每个成员，key为带引号的原始字节，val为原始值的子切片。
+// fn返回false时不再调用fn，但仍校验剩余部分，使结果与完整解析一致。
+// 当data不是合法object时返回false。
+func scanObject(data []byte, fn func(key, val []byte) bool) bool {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return false
+	}
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return skipSpace(data, i+1) == len(data)
+	}
+	for {
+		if i >= len(data) || data[i] != '"' {
+			return false
+		}
+		start := i
+		var ok bool
+		if i, ok = scanString(data, i); !ok {
+			return false
+		}
+		key := data[start:i:i]
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != ':' {
+			return false
+		}
+		i = skipSpace(data, i+1)
+		start = i
+		if i, ok = scanValueDepth(data, i, 1); !ok {
+			return false
+		}
+		if fn != nil && !fn(key, data[start:i:i]) {
+			fn = nil
+		}
+		i = skipSpace(data, i)
+		if i >= len(data) {
+			return false
+		}
+		if data[i] == '}' {
+			return skipSpace(data, i+1) == len(data)
+		}
+		if data[i] != ',' {
+			return false
+		}
+		i = skipSpace(data, i+1)
+	}
+}
+
+// scanArray遍历数组的每个元素，val为原始值的子切片。
+// fn返回false时不再调用fn，但仍校验剩余部分，使结果与完整解析一致。
+// 当data不是合法数组时返回false。
+func scanArray(data []byte, fn func(i int, val []byte) bool) bool {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '[' {
+		return false
+	}
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == ']' {
+		return skipSpace(data, i+1) == len(data)
+	}
+	for n := 0; ; n++ {
+		start := i
+		var ok bool
+		if i, ok = scanValueDepth(data, i, 1); !ok {
+			return false
+		}
+		if fn != nil && !fn(n, data[start:i:i]) {
+			fn = nil
+		}
+		i = skipSpace(data, i)
+		if i >= len(data) {
+			return false
+		}
+		if data[i] == ']' {
+			return skipSpace(data, i+1) == len(data)
+		}
+		if data[i] != ',' {
+			return false
+		}
+		i = skipSpace(data, i+1)
+	}
+}
+
+// unquoteKey将带引号的原始key解析为字符串，返回的字符串不引用原始字节。
+func unquoteKey(raw []byte) (string, bool) {
+	if t, ok := unquoteBytes(raw); ok {
+		return string(t), true
+	}
+	var key string
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return "", false
+	}
+	return key, true
+}
@@ -0,0 +1,177 @@
+package ejson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestScanValue(t *testing.T) {
+	cases := []string{
+		`null`, `true`, `false`, `nul`, `tru`, `falsy`,
+		`0`, `-0`, `123`, `-1.5e+10`, `1E3`, `01`, `-`, `1.`, `1e`, `.5`,
+		`""`, `"abc"`, `"a\"b"`, `"é"`, `"\u00g9"`, `"\x"`, "\"\t\"", `"abc`,
+		`[]`, `[1,2,3]`, `[1,]`, `[,1]`, `[1 2]`, `[`,
+		`{}`, `{"a":1}`, `{"a":1,}`, `{a:1}`, `{"a" 1}`, `{"a":}`, `{"a":1`,
+		` { "a" : [ 1 , { "b" : null } ] } `,
+	}
+	for _, c := range cases {
+		data := []byte(c)
+		i := skipSpace(data, 0)
+		end, ok := scanValue(data, i)
+		ok = ok && skipSpace(data, end) == len(data)
+		if ok != json.Valid(data) {
+			t.Fatalf("scan %q: %v, json.Valid: %v", c, ok, json.Valid(data))
+		}
+	}
+}
+
+func TestScanObject(t *testing.T) {
+	data := []byte(`{"a": 1, "bc": [true], "d": {"e": "f"}}`)
+	var keys, vals []string
+	ok := scanObject(data, func(key, val []byte) bool {
+		k, _ := unquoteKey(key)
+		keys = append(keys, k)
+		vals = append(vals, string(val))
+		return true
+	})
+	if !ok {
+		t.Fatal("scan object failed")
+	}
+	if s := fmt.Sprint(keys, vals); s != `[a bc d] [1 [true] {"e": "f"}]` {
+		t.Fatalf("scan object: %s", s)
+	}
+
+	if scanObject([]byte(`{"a":1} x`), func(key, val []byte) bool { return true }) {
+		t.Fatal("trailing data should be invalid")
+	}
+}
+
+func TestScanArray(t *testing.T) {
+	data := []byte(`[1, "2", [3], {"4": 5}]`)
+	var vals []string
+	ok := scanArray(data, func(i int, val []byte) bool {
+		vals = append(vals, string(val))
+		return i < 2
+	})
+	if !ok {
+		t.Fatal("scan array failed")
+	}
+	if s := strings.Join(vals, " "); s != `1 "2" [3]` {
+		t.Fatalf("scan array: %s", s)
+	}
+}
+
+func TestZeroCopy(t *testing.T) {
+	data := []byte(`{"a": [1, {"b": "c"}]}`)
+	g := FromBytes(data)
+	b := g.Get("a[1].b")
+	raw := b.getRaw()
+	if &raw[0] != &data[bytes.Index(data, []byte(`"c"`))] {
+		t.Fatal("child value should reference parent buffer")
+	}
+}
+
+func TestAsObjectDuplicateKey(t *testing.T) {
+	g := FromString(`{"a": 1, "a": 2}`)
+	if a := g.ObjectIndex("a").Int(); a != 2 {
+		t.Fatalf("duplicate key a: %v", a)
+	}
+}
+
+func TestAsObjectInvalid(t *testing.T) {
+	for _, s := range []string{`{"a":1`, `{"a" 1}`, `{1:1}`, `[1}`} {
+		if FromString(s).asObject() {
+			t.Fatalf("%s should not be an object", s)
+		}
+	}
+	for _, s := range []string{`[1`, `[1,]`, `{}`} {
+		if FromString(s).asArray() {
+			t.Fatalf("%s should not be an array", s)
+		}
+	}
+}
+
+func benchPayload(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `"key%d":{"id":%d,"name":"item %d","tags":["a","b","c"],"score":%d.5}`, i, i, i, i)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+func benchArrayPayload(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"id":%d,"name":"item %d","tags":["a","b","c"],"score":%d.5}`, i, i, i)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+func BenchmarkAsObject(b *testing.B) {
+	data := benchPayload(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FromBytes(data).asObject()
+	}
+}
+
+// BenchmarkAsObjectDecoder是替换前基于json.Decoder的实现，用于对比。
+func BenchmarkAsObjectDecoder(b *testing.B) {
+	data := benchPayload(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		dec.Token()
+		entry := make(map[string]*JSON)
+		var keys []string
+		for dec.More() {
+			token, _ := dec.Token()
+			key := token.(string)
+			var val json.RawMessage
+			dec.Decode(&val)
+			keys = append(keys, key)
+			entry[key] = &JSON{raw: val}
+		}
+		dec.Token()
+	}
+}
+
+func BenchmarkAsArray(b *testing.B) {
+	data := benchArrayPayload(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FromBytes(data).asArray()
+	}
+}
+
+// BenchmarkAsArrayUnmarshal是替换前基于json.Unmarshal的实现，用于对比。
+func BenchmarkAsArrayUnmarshal(b *testing.B) {
+	data := benchArrayPayload(1000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var elems []json.RawMessage
+		json.Unmarshal(data, &elems)
+		values := make([]*JSON, len(elems))
+		for i := range values {
+			values[i] = &JSON{raw: elems[i]}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ejson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// sliceRange是"[start:end:step]"切片，语义与Python切片相同：
+// 下标可以为负数，超出范围的下标自动截断，step为负数时倒序选取。
+type sliceRange struct {
+	start, end *int // nil表示省略
+	step       int
+}
+
+// indices返回长度为n的数组中被选中的下标，按选取顺序排列。
+func (r *sliceRange) indices(n int) []int {
+	bound := func(p *int, def, lo, hi int) int {
+		if p == nil {
+			return def
+		}
+		i := *p
+		if i < 0 {
+			i += n
+		}
+		return min(max(i, lo), hi)
+	}
+
+	var idx []int
+	if r.step > 0 {
+		start, end := bound(r.start, 0, 0, n), bound(r.end, n, 0, n)
+		for i := start; i < end; i += r.step {
+			idx = append(idx, i)
+		}
+	} else {
+		start, end := bound(r.start, n-1, -1, n-1), bound(r.end, -1, -1, n-1)
+		for i := start; i > end; i += r.step {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// parseSlice解析"[start:end:step]"，content是方括号内的内容。
+func (p *keyParser) parseSlice(start int, content string) (segment, error) {
+	parts := strings.Split(content, ":")
+	if len(parts) > 3 {
+		return segment{}, p.errorf(start, "invalid slice %q", content)
+	}
+
+	r := &sliceRange{step: 1}
+	var bounds [3]*int
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return segment{}, p.errorf(start, "invalid slice %q", content)
+		}
+		bounds[i] = &n
+	}
+	r.start, r.end = bounds[0], bounds[1]
+	if bounds[2] != nil {
+		if *bounds[2] == 0 {
+			return segment{}, p.errorf(start, "slice step cannot be zero")
+		}
+		r.step = *bounds[2]
+	}
+	return segment{kind: segSlice, slice: r}, nil
+}
+
+// appendSliced将children中被切片s选中的值追加到dst，children必须是数组的所有元素。
+func appendSliced[T any](dst []T, s segment, children []T) []T {
+	for _, i := range s.slice.indices(len(children)) {
+		dst = append(dst, children[i])
+	}
+	return dst
+}
@@ -0,0 +1,72 @@
+package ejson
+
+import "testing"
+
+func TestSlice(t *testing.T) {
+	g := FromString(`{"items":[0,1,2,3,4,5],"obj":{"a":1},"s":"[0,1,2]","users":[{"id":1},{"id":2},{"id":3}]}`)
+	cases := map[string]string{
+		"items[1:4]":         `[1,2,3]`,
+		"items[-3:]":         `[3,4,5]`,
+		"items[:2]":          `[0,1]`,
+		"items[::2]":         `[0,2,4]`,
+		"items[::-1]":        `[5,4,3,2,1,0]`,
+		"items[4:1:-2]":      `[4,2]`,
+		"items[:]":           `[0,1,2,3,4,5]`,
+		"items[-100:100]":    `[0,1,2,3,4,5]`,
+		"items[4:2]":         `[]`,
+		"items[1:3][0]":      `[null,null]`,
+		"obj[0:1]":           `[]`,
+		"s[1:]":              `[1,2]`,
+		"users[1:].id":       `[2,3]`,
+		"users[:2].*":        `[1,2]`,
+		"..[1:2]":            `[1,1,{"id":2}]`,
+		"users[?@.id>1][:1]": `[]`,
+	}
+	for key, want := range cases {
+		if s := g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get %v: %s, should be %s", key, s, want)
+		}
+		if s := g.Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup %v: %s, should be %s", key, s, want)
+		}
+		if s := g.GetAll(key).String(); s != want {
+			t.Fatalf("get all %v: %s, should be %s", key, s, want)
+		}
+	}
+
+	for _, key := range []string{"items[1:2:3:4]", "items[a:]", "items[::0]", "items[1:"} {
+		if _, err := g.GetE(key); err == nil {
+			t.Fatalf("%v should be invalid", key)
+		}
+	}
+}
+
+func TestSliceModify(t *testing.T) {
+	g := FromString(`{"items":[0,1,2,3,4,5]}`)
+
+	var got []int64
+	g.GetAll("items[1:4]").Each(func(i int, js *JSON) bool {
+		got = append(got, js.Int())
+		return true
+	})
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Fatalf("each: %v", got)
+	}
+
+	g.Get("items[::2]").Set(-1)
+	if s := g.String(); s != `{"items":[-1,1,-1,3,-1,5]}` {
+		t.Fatalf("set slice: %s", s)
+	}
+
+	g.Get("items[-3:]").Remove()
+	if s := g.String(); s != `{"items":[-1,1,-1]}` {
+		t.Fatalf("remove slice: %s", s)
+	}
+
+	// 先读取再切片，与未读取时结果一致
+	g = FromString(`{"items":[0,1,2,3]}`)
+	g.Get("items").Len()
+	if s := g.Lookup("items[1::2]").String(); s != `[1,3]` {
+		t.Fatalf("lookup materialized slice: %s", s)
+	}
+}
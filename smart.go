@@ -6,87 +6,369 @@ import (
 	"strings"
 )
 
-type entryFunc func(*JSON) *JSON
-
-func invalidFormat(i int, s string) error {
-	return fmt.Errorf("ejson: smart key %vth part invalid format: '%v'", i, s)
-}
-
-func parseSmartKey(keys string) ([]entryFunc, error) {
-	var entries []entryFunc
-	parts := strings.Split(keys, ".")
-	for i := 0; i < len(parts); i++ {
-		part := parts[i]
-		l := strings.IndexByte(part, '[')
-		r := strings.IndexByte(part, ']')
-		if l < 0 {
-			if r >= 0 {
-				return nil, invalidFormat(i, part)
-			}
+type segmentKind uint8
+
+const (
+	segKey      segmentKind = iota // "key"，数组可以通过数字key访问
+	segIndex                       // "[0]"，只能作为数组下标
+	segWildcard                    // "*"或"[*]"，匹配object或array的所有值
+	segFilter                      // "[?(expr)]"，匹配object或array中满足expr的值
+	segSlice                       // "[start:end:step]"，匹配array中的一段值
+	segFunc                        // "@name"或"#name"，调用路径函数
+	segAlt                         // "(a|b)"，匹配第一个存在的key
+	segAppend                      // "[+]"，array末尾之后的位置，写入即追加
+	segPrepend                     // "[-]"，array开头之前的位置，写入即插入到开头
+)
+
+// segment是smart key中的一段，形如"key"、"[0]"或"*"。
+type segment struct {
+	kind    segmentKind
+	key     string
+	index   int
+	numeric bool // key可以作为数组下标
+	descend bool // "..key"，匹配任意深度的值
+	filter  filterExpr
+	slice   *sliceRange
+	fn      Func
+	alts    []segment
+	match   KeyMatch // 至少按match匹配key，见(*Path).WithKeyMatch
+}
+
+func keySegment(key string) segment {
+	idx, err := strconv.Atoi(key)
+	return segment{kind: segKey, key: key, index: idx, numeric: err == nil}
+}
+
+func indexSegment(idx int) segment {
+	return segment{kind: segIndex, index: idx}
+}
+
+// indexable判断s是否可以作为数组下标。
+func (s segment) indexable() bool {
+	return s.kind == segIndex || (s.kind == segKey && s.numeric)
+}
+
+// fanout判断s是否可能匹配多个值。
+func (s segment) fanout() bool {
+	return s.kind == segWildcard || s.kind == segFilter || s.kind == segSlice || s.descend
+}
 
-			entries = append(entries, func(j *JSON) *JSON {
-				if j.IsStr() && j.StrIsJSON() {
-					j = j.StrJSON()
-				}
-				if j.IsArray() {
-					if idx, err := strconv.Atoi(part); err == nil {
-						return j.ArrayIndex(idx)
-					}
-				}
-				return j.ObjectIndex(part)
-			})
-			continue
-		}
-
-		if l == 0 {
-			if i > 0 { // "[0]"
-				return nil, invalidFormat(i, part)
+func fanout(segs []segment) bool {
+	for _, s := range segs {
+		if s.fanout() {
+			return true
+		}
+	}
+	return false
+}
+
+// get与(*JSON).Get语义一致：字符串json会自动展开，
+// 数组可以通过数字key访问，其余情况按object key访问。
+func (s segment) get(j *JSON) *JSON {
+	if s.kind == segFunc {
+		return s.apply(j)
+	}
+	if s.kind == segAlt {
+		return s.getAlt(j)
+	}
+	if j.IsStr() && j.StrIsJSON() {
+		j = j.StrJSON()
+	}
+	if s.kind == segIndex {
+		return j.ArrayIndex(s.index)
+	}
+	if s.kind == segAppend || s.kind == segPrepend {
+		return j.pendingArray().Pending(s.kind == segPrepend)
+	}
+	if s.numeric && j.IsArray() {
+		return j.ArrayIndex(s.index)
+	}
+	return j.matchIndex(s.key, s.match)
+}
+
+// appendAll将s在j中匹配到的所有值追加到dst。
+func (s segment) appendAll(dst []*JSON, j *JSON) []*JSON {
+	if s.descend {
+		return s.appendDescendants(dst, j)
+	}
+	if !s.fanout() {
+		return append(dst, s.get(j))
+	}
+	if j.IsStr() && j.StrIsJSON() {
+		j = j.StrJSON()
+	}
+	if j.IsArray() && j.asArray() {
+		if s.kind == segSlice {
+			return appendSliced(dst, s, j.array.values)
+		}
+		for _, v := range j.array.values {
+			if s.accept(cursor{node: v}) {
+				dst = append(dst, v)
 			}
-			if r <= 0 {
-				return nil, invalidFormat(i, part)
+		}
+		return dst
+	}
+	if s.kind == segSlice {
+		return dst
+	}
+	if j.IsObject() && j.asObject() {
+		for _, key := range j.object.keys {
+			if v := j.object.entry[key]; s.accept(cursor{node: v}) {
+				dst = append(dst, v)
 			}
 		}
-		if l > 0 {
-			if (r < 0) || (r > 0 && r <= l) {
-				return nil, invalidFormat(i, part)
+	}
+	return dst
+}
+
+// accept判断通配符或过滤片段是否匹配子节点c。
+func (s segment) accept(c cursor) bool {
+	if s.kind == segFilter {
+		return s.filter.eval(c)
+	}
+	return true
+}
+
+// PathError表示smart key语法错误。
+type PathError struct {
+	Key     string // 完整的smart key
+	Segment string // 出错的片段
+	Offset  int    // Segment在Key中的字节偏移
+	Reason  string
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("ejson: invalid smart key %q at offset %v: %v in %q",
+		e.Key, e.Offset, e.Reason, e.Segment)
+}
+
+type keyParser struct {
+	key string
+	pos int
+}
+
+func (p *keyParser) eof() bool {
+	return p.pos >= len(p.key)
+}
+
+func (p *keyParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.key[p.pos]
+}
+
+// errorf返回从offset开始的片段的语法错误，片段截止到下一个未转义且不在引号、方括号内的'.'。
+func (p *keyParser) errorf(offset int, format string, args ...any) *PathError {
+	end := len(p.key)
+	quoted := false
+	depth := 0
+loop:
+	for i := offset; i < len(p.key); i++ {
+		switch p.key[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case '[':
+			if !quoted {
+				depth++
+			}
+		case ']':
+			if !quoted {
+				depth--
 			}
+		case '.':
+			if !quoted && depth <= 0 && i > offset {
+				end = i
+				break loop
+			}
+		}
+	}
+	return &PathError{
+		Key:     p.key,
+		Segment: p.key[offset:end],
+		Offset:  offset,
+		Reason:  fmt.Sprintf(format, args...),
+	}
+}
+
+func parseSmartKey(key string) ([]segment, error) {
+	p := &keyParser{key: key}
+	return p.parse()
+}
 
-			entries = append(entries, func(j *JSON) *JSON {
-				if j.IsStr() && j.StrIsJSON() {
-					j = j.StrJSON()
-				}
-				if j.IsArray() {
-					if idx, err := strconv.Atoi(part[:l]); err == nil {
-						return j.ArrayIndex(idx)
-					}
-				}
-				return j.ObjectIndex(part[:l])
-			})
-		}
-
-		tmp := part[l:]
-		for tmp != "" {
-			if tmp[0] != '[' {
-				return nil, invalidFormat(i, part)
+func (p *keyParser) parse() ([]segment, error) {
+	var segs []segment
+	for part := 0; ; part++ {
+		start := p.pos
+
+		// "..key"匹配任意深度的key
+		descend := false
+		if part == 0 && strings.HasPrefix(p.key, "..") {
+			p.pos += 2
+			descend = true
+		} else if part > 0 && p.peek() == '.' {
+			p.pos++
+			descend = true
+		}
+		if descend && (p.eof() || p.peek() == '.') {
+			return nil, p.errorf(start, "missing key after '..'")
+		}
+
+		first := len(segs)
+		if p.peek() == '[' {
+			if part > 0 && !descend { // "a.[0]"
+				return nil, p.errorf(start, "unexpected '['")
 			}
-			r = strings.IndexByte(tmp, ']')
-			if r < 0 {
-				return nil, invalidFormat(i, part)
+		} else {
+			seg, err := p.parseKey(start)
+			if err != nil {
+				return nil, err
 			}
-			idx, err := strconv.Atoi(tmp[1:r])
+			segs = append(segs, seg)
+		}
+
+		for p.peek() == '[' {
+			seg, err := p.parseBracket(start)
 			if err != nil {
-				return nil, fmt.Errorf("ejson: smart key %vth invalid index: '%v'",
-					i, part)
+				return nil, err
+			}
+			segs = append(segs, seg)
+		}
+		if descend {
+			if segs[first].kind == segFunc {
+				return nil, p.errorf(start, "unexpected func after '..'")
+			}
+			segs[first].descend = true
+		}
+
+		if p.eof() {
+			return segs, nil
+		}
+		if c := p.peek(); c != '.' {
+			return nil, p.errorf(start, "unexpected '%c'", c)
+		}
+		p.pos++
+	}
+}
+
+// parseBracket解析形如"[0]"、"[*]"、"[+]"、"[-]"、"[1:3]"或"[?(expr)]"的片段。
+func (p *keyParser) parseBracket(start int) (segment, error) {
+	if strings.HasPrefix(p.key[p.pos:], "[?") {
+		return p.parseFilter(start)
+	}
+	r := strings.IndexByte(p.key[p.pos:], ']')
+	if r < 0 {
+		return segment{}, p.errorf(start, "missing ']'")
+	}
+	r += p.pos
+	content := p.key[p.pos+1 : r]
+	p.pos = r + 1
+	switch content {
+	case "*":
+		return segment{kind: segWildcard}, nil
+	case "+":
+		return segment{kind: segAppend}, nil
+	case "-":
+		return segment{kind: segPrepend}, nil
+	}
+	if strings.IndexByte(content, ':') >= 0 {
+		return p.parseSlice(start, content)
+	}
+	idx, err := strconv.Atoi(content)
+	if err != nil {
+		return segment{}, p.errorf(start, "invalid index %q", content)
+	}
+	return indexSegment(idx), nil
+}
+
+// parseKey解析object key或通配符"*"，key支持两种转义方式：
+//   - 双引号括起的key，如"app.kubernetes.io/name"，引号内'\'转义下一个字符；
+//   - 未加引号的key中，'\'转义下一个字符，如user\.name。
+//
+// 加引号的key只作为object key，不会作为数组下标。
+func (p *keyParser) parseKey(start int) (segment, error) {
+	if p.peek() == '(' {
+		return p.parseAlt(start)
+	}
+	if p.peek() == '"' {
+		key, err := p.parseQuoted(start)
+		if err != nil {
+			return segment{}, err
+		}
+		if c := p.peek(); c != 0 && c != '.' && c != '[' {
+			return segment{}, p.errorf(start, "unexpected '%c' after quoted key", c)
+		}
+		return segment{kind: segKey, key: key}, nil
+	}
+
+	end := strings.IndexAny(p.key[p.pos:], keyDelims)
+	if end < 0 {
+		end = len(p.key)
+	} else {
+		end += p.pos
+	}
+	key := p.key[p.pos:end]
+	p.pos = end
+	if key == "*" {
+		return segment{kind: segWildcard}, nil
+	}
+	return keyOrFuncSegment(key), nil
+}
+
+// parseQuoted解析双引号括起的key，'\\'转义下一个字符。
+func (p *keyParser) parseQuoted(start int) (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf(start, "missing closing '\"'")
+		}
+		c := p.key[p.pos]
+		p.pos++
+		if c == '"' {
+			return b.String(), nil
+		}
+		if c == '\\' {
+			if p.eof() {
+				return "", p.errorf(start, "missing closing '\"'")
 			}
-			entries = append(entries, func(j *JSON) *JSON {
-				if j.IsStr() && j.StrIsJSON() {
-					j = j.StrJSON()
-				}
-				return j.ArrayIndex(idx)
-			})
+			c = p.key[p.pos]
+			p.pos++
+		}
+		b.WriteByte(c)
+	}
+}
+
+// keyDelims是未加引号的key中不能出现的字符，包含它们的key需要用双引号括起。
+// 未加引号的key中'\'是普通字符，如C:\dir表示key C:\dir。
+const keyDelims = ".[]"
 
-			tmp = tmp[r+1:]
+// QuoteKey返回可在smart key中表示key的片段。
+// 当key为空、包含'.'、'['、']'等特殊字符或是通配符"*"时，返回加双引号并转义的key，否则原样返回。
+func QuoteKey(key string) string {
+	if !needQuote(key) {
+		return key
+	}
+	var b strings.Builder
+	b.Grow(len(key) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(key); i++ {
+		if key[i] == '"' || key[i] == '\\' {
+			b.WriteByte('\\')
 		}
+		b.WriteByte(key[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func needQuote(key string) bool {
+	if key == "" || key == "*" || key[0] == '"' || key[0] == '(' || lookupFunc(key) != nil {
+		return true
 	}
-	return entries, nil
+	return strings.ContainsAny(key, keyDelims)
 }
@@ -0,0 +1,127 @@
+package ejson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSmartKey(t *testing.T) {
+	valid := []string{"", "a", "a.b", "[0]", "[0][1].a", "a[0][-1].b", "a.0.b", "a..b", "a."}
+	for _, key := range valid {
+		if _, err := parseSmartKey(key); err != nil {
+			t.Fatalf("%q should be valid: %v", key, err)
+		}
+	}
+
+	invalid := []struct {
+		key     string
+		segment string
+		offset  int
+	}{
+		{"a.[0]", "[0]", 2},
+		{"a[x]", "a[x]", 0},
+		{"a.b[0", "b[0", 2},
+		{"a]", "a]", 0},
+		{"a.b[0]c.d", "b[0]c", 2},
+		{"x.a[1]]", "a[1]]", 2},
+	}
+	for _, c := range invalid {
+		_, err := parseSmartKey(c.key)
+		var pe *PathError
+		if !errors.As(err, &pe) {
+			t.Fatalf("%q should be invalid, err: %v", c.key, err)
+		}
+		if pe.Key != c.key || pe.Segment != c.segment || pe.Offset != c.offset {
+			t.Fatalf("%q error: %+v", c.key, pe)
+		}
+	}
+}
+
+func TestGetE(t *testing.T) {
+	g := FromString(`{"a":[1]}`)
+	v, err := g.GetE("a[0]")
+	if err != nil || v.Int() != 1 {
+		t.Fatalf("a[0]: %v, %v", v, err)
+	}
+
+	v, err = g.GetE("a.[0]")
+	if err == nil {
+		t.Fatal("a.[0] should return an error")
+	}
+	if v == nil || v.Exists() {
+		t.Fatal("a.[0] should not exists")
+	}
+}
+
+func TestStrict(t *testing.T) {
+	SetStrict(true)
+	defer SetStrict(false)
+	defer func() {
+		if _, ok := recover().(*PathError); !ok {
+			t.Fatal("strict mode should panic with *PathError")
+		}
+	}()
+	FromString(`{}`).Get("a[x]")
+}
+
+func TestQuotedKey(t *testing.T) {
+	g := FromString(`{"labels":{"app.kubernetes.io/name":"web","tags[]":["a"],"a\"b":1,"0":"zero","C:\\dir":2,"a.b\\c":3},"user.name":"eachain"}`)
+	cases := map[string]string{
+		`labels."app.kubernetes.io/name"`: `"web"`,
+		`labels."tags[]"[0]`:              `"a"`,
+		`labels."a\"b"`:                   `1`,
+		`labels.a"b`:                      `1`,
+		`"user.name"`:                     `"eachain"`,
+		`labels."0"`:                      `"zero"`,
+		`labels.C:\dir`:                   `2`, // 未加引号的'\'是普通字符
+		`labels."C:\\dir"`:                `2`,
+		`labels."a.b\\c"`:                 `3`,
+		`labels.app\.kubernetes\.io/name`: ``,
+	}
+	for key, want := range cases {
+		if s := g.Get(key).UnsafeString(); s != want {
+			t.Fatalf("get %v: %q, should be %q", key, s, want)
+		}
+		if s := g.Lookup(key).UnsafeString(); s != want {
+			t.Fatalf("lookup %v: %q, should be %q", key, s, want)
+		}
+	}
+
+	if FromString(`["x"]`).Get(`"0"`).Exists() {
+		t.Fatal(`quoted key "0" should not index an array`)
+	}
+
+	g = new(JSON)
+	g.Get(`C:\dir`).Set(1)
+	if s := g.UnsafeString(); s != `{"C:\\dir":1}` {
+		t.Fatalf("set backslash key: %s", s)
+	}
+
+	g = new(JSON)
+	g.Get(`metadata.labels."app.kubernetes.io/name"`).Set("web")
+	if s := g.UnsafeString(); s != `{"metadata":{"labels":{"app.kubernetes.io/name":"web"}}}` {
+		t.Fatalf("set: %s", s)
+	}
+
+	for _, key := range []string{`"abc`, `a."b`, `"a"b`} {
+		if _, err := parseSmartKey(key); err == nil {
+			t.Fatalf("%q should be invalid", key)
+		}
+	}
+}
+
+func TestQuoteKey(t *testing.T) {
+	keys := []string{"a", "", "a.b", "a[0]", `a"b`, `"a`, `a\b`, "0"}
+	for _, key := range keys {
+		segs, err := parseSmartKey(QuoteKey(key))
+		if err != nil {
+			t.Fatalf("quote %q: %v", key, err)
+		}
+		if len(segs) != 1 || segs[0].key != key {
+			t.Fatalf("quote %q: %v", key, QuoteKey(key))
+		}
+	}
+	if QuoteKey("a.b") != `"a.b"` {
+		t.Fatalf("quote a.b: %v", QuoteKey("a.b"))
+	}
+}
@@ -72,7 +72,7 @@ func (str *strjson) Elem() *JSON {
 
 	parent := str.parent
 	g.update = str.update.append(func() {
-		if str.parent != parent {
+		if str.parent != parent || g.parent != parent {
 			return
 		}
 		str.value = g
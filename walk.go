@@ -0,0 +1,107 @@
+package ejson
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Walk的回调函数可以返回以下错误控制遍历过程，Walk本身不会返回它们。
+var (
+	// SkipChildren表示不遍历当前值的子节点，只在先序遍历中生效。
+	SkipChildren = errors.New("ejson: skip children")
+	// SkipAll表示停止遍历。
+	SkipAll = errors.New("ejson: skip all")
+)
+
+// WalkFunc是Walk的回调函数，path是node相对于遍历起点的路径，起点的path为空。
+// 返回SkipChildren、SkipAll以外的错误时停止遍历，并由Walk返回该错误。
+type WalkFunc func(path Path, node *JSON) error
+
+// Walk先序遍历当前*JSON及其所有后代，先访问object、array本身，再按顺序访问它的值。
+// 内容为json的字符串不会展开。
+//
+// fn可以修改node：子节点在fn返回后读取，因此fn写入的新值会被继续遍历；
+// 同一object或array中的值在访问第一个值之前确定，遍历过程中增删兄弟节点不影响本次遍历。
+func (g *JSON) Walk(fn WalkFunc) error {
+	return ignoreSkipAll(walk(g, Path{}, fn, false))
+}
+
+// WalkPostOrder后序遍历当前*JSON及其所有后代，先访问object、array的值，再访问它本身。
+// fn返回SkipChildren与返回nil相同。
+func (g *JSON) WalkPostOrder(fn WalkFunc) error {
+	return ignoreSkipAll(walk(g, Path{}, fn, true))
+}
+
+func ignoreSkipAll(err error) error {
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+func walk(g *JSON, path Path, fn WalkFunc, post bool) error {
+	if !post {
+		if err := fn(path, g); err != nil {
+			if err == SkipChildren {
+				return nil
+			}
+			return err
+		}
+	}
+
+	switch {
+	case g.results != nil:
+		for i, c := range g.results.Nodes() {
+			if err := walk(c, path.elem(i), fn, post); err != nil {
+				return err
+			}
+		}
+	case g.IsArray() && g.asArray():
+		values := make([]*JSON, len(g.array.values))
+		copy(values, g.array.values)
+		for i, c := range values {
+			if err := walk(c, path.elem(i), fn, post); err != nil {
+				return err
+			}
+		}
+	case g.IsObject() && g.asObject():
+		keys := g.Keys()
+		values := make([]*JSON, len(keys))
+		for i, key := range keys {
+			values[i] = g.object.entry[key]
+		}
+		for i, c := range values {
+			if err := walk(c, path.child(keys[i]), fn, post); err != nil {
+				return err
+			}
+		}
+	}
+
+	if post {
+		if err := fn(path, g); err != nil && err != SkipChildren {
+			return err
+		}
+	}
+	return nil
+}
+
+// child返回p下key对应的路径。
+func (p Path) child(key string) Path {
+	seg := segment{kind: segKey, key: key}
+	name := QuoteKey(key)
+	if name == key {
+		seg = keySegment(key)
+	}
+	if p.key != "" {
+		name = p.key + "." + name
+	}
+	return Path{key: name, segs: append(p.segs[:len(p.segs):len(p.segs)], seg)}
+}
+
+// elem返回p下第i个元素的路径。
+func (p Path) elem(i int) Path {
+	return Path{
+		key:  p.key + "[" + strconv.Itoa(i) + "]",
+		segs: append(p.segs[:len(p.segs):len(p.segs)], indexSegment(i)),
+	}
+}
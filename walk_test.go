@@ -0,0 +1,113 @@
+package ejson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	g := FromString(`{"a":[1,{"b":2}],"c.d":{"e":null},"s":"{\"x\":1}"}`)
+
+	var pre []string
+	err := g.Walk(func(path Path, node *JSON) error {
+		pre = append(pre, path.String()+"="+node.Kind().String())
+		if path.Get(g) != node {
+			t.Fatalf("path %v does not point to node", path.String())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `=object a=array a[0]=number a[1]=object a[1].b=number "c.d"=object "c.d".e=null s=string`
+	if s := strings.Join(pre, " "); s != want {
+		t.Fatalf("pre order: %s", s)
+	}
+
+	var post []string
+	g.WalkPostOrder(func(path Path, node *JSON) error {
+		post = append(post, path.String())
+		return SkipChildren
+	})
+	want = `a[0] a[1].b a[1] a "c.d".e "c.d" s `
+	if s := strings.Join(post, " "); s != want {
+		t.Fatalf("post order: %q", s)
+	}
+}
+
+func TestWalkHoles(t *testing.T) {
+	// 写入越界下标时跳过的位置按null遍历
+	g := FromString(`{"a":[]}`)
+	g.Get("a[2].x").Set(1)
+	var paths []string
+	err := g.Walk(func(path Path, node *JSON) error {
+		paths = append(paths, path.String()+"="+node.Kind().String())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `=object a=array a[0]=null a[1]=null a[2]=object a[2].x=number`
+	if s := strings.Join(paths, " "); s != want {
+		t.Fatalf("walk holes: %s", s)
+	}
+}
+
+func TestWalkSkip(t *testing.T) {
+	g := FromString(`{"a":{"b":1},"c":[2,3],"d":4}`)
+
+	var paths []string
+	err := g.Walk(func(path Path, node *JSON) error {
+		paths = append(paths, path.String())
+		if path.String() == "a" {
+			return SkipChildren
+		}
+		if path.String() == "c[0]" {
+			return SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.Join(paths, ","); s != ",a,c,c[0]" {
+		t.Fatalf("skip: %s", s)
+	}
+
+	stop := errors.New("stop")
+	err = g.WalkPostOrder(func(path Path, node *JSON) error {
+		if node.Kind() == Number {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("walk error: %v", err)
+	}
+}
+
+func TestWalkModify(t *testing.T) {
+	g := FromString(`{"a":{"b":1},"c":[1,2],"d":{"secret":"x"}}`)
+	err := g.Walk(func(path Path, node *JSON) error {
+		if key, _ := node.Key(); key == "secret" {
+			node.Remove()
+			return nil
+		}
+		if path.String() == "a" {
+			// 新值会继续遍历
+			node.Set(map[string]int{"n": 1})
+			return nil
+		}
+		if node.Kind() == Number {
+			node.Set(node.Int() * 10)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := g.String(); s != `{"a":{"n":10},"c":[10,20],"d":{}}` {
+		t.Fatalf("modify: %s", s)
+	}
+}